@@ -4,6 +4,8 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"wingspan-ops/internal/esi"
 	"wingspan-ops/internal/routing"
@@ -48,7 +50,11 @@ func main() {
 		RedirectURL:  os.Getenv("EVE_CALLBACK_URL"), // e.g., http://localhost:8080/callback
 		ClientID:     clientID,
 		ClientSecret: secretKey,
-		Scopes:       []string{}, // No specific scopes needed for just identity
+		Scopes: []string{
+			"esi-ui.write_waypoint.v1",       // set the in-game autopilot route
+			"esi-location.read_location.v1",  // prefill the route start with the current system
+			"esi-location.read_ship_type.v1", // and the ship class with the current hull
+		},
 		Endpoint: oauth2.Endpoint{
 			AuthURL:  "https://login.eveonline.com/v2/oauth/authorize",
 			TokenURL: "https://login.eveonline.com/v2/oauth/token",
@@ -82,9 +88,63 @@ func main() {
 	}
 	log.Printf("✅ Loaded %d systems into the static stargate graph.", graph.StaticAdjacencyListSize())
 
-	// Start a background process to update EVE Online kill data.
+	// System coordinates from the SDE, for the capital jump planner. Without
+	// them the planner is switched off but everything else still works.
+	// SDE_SOLAR_SYSTEMS_FILE points at the SDE's mapSolarSystems.csv, e.g.
+	// https://www.fuzzwork.co.uk/dump/latest/mapSolarSystems.csv, which the
+	// Docker image downloads at build time; it defaults to the working directory.
+	sdePath := os.Getenv("SDE_SOLAR_SYSTEMS_FILE")
+	if sdePath == "" {
+		sdePath = "mapSolarSystems.csv"
+	}
+	jumpSpace, err := routing.LoadJumpSpace(sdePath)
+	if err != nil {
+		log.Printf("WARN: Could not load system coordinates, capital planner disabled: %v", err)
+	} else {
+		log.Printf("✅ Loaded coordinates for %d systems.", jumpSpace.Len())
+	}
+
+	// Characters allowed to edit shared settings such as the jump bridge list.
+	var adminIDs []int
+	for _, field := range strings.Split(os.Getenv("ADMIN_CHARACTER_IDS"), ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		id, err := strconv.Atoi(field)
+		if err != nil {
+			log.Fatalf("FATAL: Invalid character ID %q in ADMIN_CHARACTER_IDS.", field)
+		}
+		adminIDs = append(adminIDs, id)
+	}
+	bridgesPath := os.Getenv("JUMP_BRIDGES_FILE")
+	if bridgesPath == "" {
+		bridgesPath = "bridges.json"
+	}
+
+	// SSO tokens are saved encrypted with TOKEN_STORE_KEY, 32 random bytes
+	// base64 encoded (openssl rand -base64 32). Without it they are kept in
+	// memory and characters must log in again after a restart.
+	tokensPath := os.Getenv("TOKEN_STORE_FILE")
+	if tokensPath == "" {
+		tokensPath = "tokens.enc"
+	}
+	tokenKey := os.Getenv("TOKEN_STORE_KEY")
+	if tokenKey == "" {
+		log.Println("WARN: TOKEN_STORE_KEY is not set. SSO tokens will only be kept in memory, so every character must log in again after a restart and in-game actions stop working until they do. Set it to the output of: openssl rand -base64 32")
+	}
+
+	// The access list of corporations, alliances and characters allowed in.
+	// Until admins save one, only WINGSPAN is let in.
+	aclPath := os.Getenv("ACL_FILE")
+	if aclPath == "" {
+		aclPath = "acl.json"
+	}
+
+	// Start a background process to update EVE Online kill data. The server
+	// scores route risk against the baseline it keeps.
+	killBaselinePath := "kills_baseline.json"
 	var wg sync.WaitGroup
-	killUpdater := updater.New(esiClient, "kills.json")
+	killUpdater := updater.New(esiClient, "kills.json", killBaselinePath)
 	wg.Add(1)
 	go killUpdater.Start(&wg)
 
@@ -94,13 +154,58 @@ func main() {
 		feedbackURL,
 		esiClient,
 		graph,
+		jumpSpace,
 		oauthConfig,
 		sessionStore,
+		bridgesPath,
+		adminIDs,
+		tokensPath,
+		tokenKey,
+		aclPath,
+		killBaselinePath,
 	)
 	if err != nil {
 		log.Fatalf("FATAL: Failed to create server: %v", err)
 	}
 
+	// Add the saved jump bridge network to the routing graph.
+	if err := srv.LoadBridges(); err != nil {
+		log.Printf("WARN: Could not load jump bridges: %v", err)
+	}
+
+	// Load the SSO tokens kept for authenticated ESI calls.
+	if err := srv.LoadTokens(); err != nil {
+		log.Printf("WARN: Could not load SSO tokens: %v", err)
+	}
+
+	// Load the access list deciding who may log in.
+	if err := srv.LoadAccessList(); err != nil {
+		log.Printf("WARN: Could not load access list, only WINGSPAN is allowed: %v", err)
+	}
+
+	// Load the characters each pilot has linked to their account.
+	if err := srv.LoadAccounts(); err != nil {
+		log.Printf("WARN: Could not load accounts: %v", err)
+	}
+
+	// Load the avoid lists characters have saved.
+	if err := srv.LoadAvoidLists(); err != nil {
+		log.Printf("WARN: Could not load avoid lists: %v", err)
+	}
+
+	// Load the routes pilots are watching for shorter connections.
+	if err := srv.LoadWatches(); err != nil {
+		log.Printf("WARN: Could not load route watches: %v", err)
+	}
+
+	// Keep the wormhole overlay used for routing up to date in the background.
+	wg.Add(1)
+	go srv.StartWormholeRefresher(&wg)
+
+	// Re-check linked characters against the access list in the background.
+	wg.Add(1)
+	go srv.StartMembershipChecker(&wg)
+
 	// Register all the HTTP routes.
 	router := srv.RegisterRoutes()
 
@@ -43,6 +43,70 @@ type esiCharacterIDResult struct {
 	} `json:"characters"`
 }
 
+// CharacterLocation is where a character is and what they are flying.
+type CharacterLocation struct {
+	SolarSystemID int
+	ShipTypeID    int
+	ShipName      string
+}
+
+type esiCharacterLocation struct {
+	SolarSystemID int `json:"solar_system_id"`
+}
+
+type esiCharacterShip struct {
+	ShipTypeID int    `json:"ship_type_id"`
+	ShipName   string `json:"ship_name"`
+}
+
+type esiTypeInfo struct {
+	GroupID int `json:"group_id"`
+}
+
+type esiLocationIDResult struct {
+	Constellations []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"constellations"`
+	Regions []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"regions"`
+}
+
+// affiliationBatchSize is the most characters ESI will look up in one affiliation call.
+const affiliationBatchSize = 1000
+
+// CharacterAffiliation is the corporation and alliance a character is in.
+type CharacterAffiliation struct {
+	CharacterID   int `json:"character_id"`
+	CorporationID int `json:"corporation_id"`
+	AllianceID    int `json:"alliance_id"` // 0 when the corporation is in no alliance
+}
+
+// EntityIDs holds the characters, corporations and alliances a set of names
+// resolved to, keyed by lower-cased name.
+type EntityIDs struct {
+	Characters   map[string]int
+	Corporations map[string]int
+	Alliances    map[string]int
+}
+
+type esiEntityIDResult struct {
+	Characters []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"characters"`
+	Corporations []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"corporations"`
+	Alliances []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"alliances"`
+}
+
 // ESIClient manages all communication with the EVE Online ESI.
 type ESIClient struct {
 	httpClient      *http.Client
@@ -53,6 +117,7 @@ type ESIClient struct {
 	systemIDCache   map[string]int         // Name -> ID (from local file)
 	nameCache       map[int]string         // ID -> Name (from live API calls)
 	systemInfoCache map[int]*ESISystemInfo // ID -> Full Info (from local file)
+	typeGroupCache  map[int]int            // type ID -> group ID (from live API calls)
 }
 
 // --- Constructor ---
@@ -66,6 +131,7 @@ func NewESIClient(contactInfo string) *ESIClient {
 		systemNameCache: make(map[string]string),
 		systemIDCache:   make(map[string]int),
 		systemInfoCache: make(map[int]*ESISystemInfo),
+		typeGroupCache:  make(map[int]int),
 	}
 }
 
@@ -111,6 +177,13 @@ func (c *ESIClient) LoadSystemNameCache(filename string) error {
 
 // --- Core HTTP Helper ---
 func (c *ESIClient) do(ctx context.Context, method, endpoint string, body io.Reader, target any) error {
+	return c.doAuthed(ctx, method, endpoint, "", body, target)
+}
+
+// doAuthed is do for endpoints that act on behalf of a character. An empty
+// accessToken sends the request without authorization. A nil target is for
+// endpoints that answer 204 No Content.
+func (c *ESIClient) doAuthed(ctx context.Context, method, endpoint, accessToken string, body io.Reader, target any) error {
 	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, body)
 	if err != nil {
 		return err
@@ -120,6 +193,9 @@ func (c *ESIClient) do(ctx context.Context, method, endpoint string, body io.Rea
 	if method == http.MethodPost {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -127,6 +203,9 @@ func (c *ESIClient) do(ctx context.Context, method, endpoint string, body io.Rea
 	}
 	defer resp.Body.Close()
 
+	if target == nil && resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("ESI returned non-200 status: %s", resp.Status)
 	}
@@ -232,6 +311,34 @@ func (c *ESIClient) GetCharacterID(ctx context.Context, name string) (int64, err
 	return idData.Characters[0].ID, nil
 }
 
+// GetConstellationID resolves a constellation name to its ID.
+func (c *ESIClient) GetConstellationID(ctx context.Context, name string) (int, error) {
+	var idData esiLocationIDResult
+	body, _ := json.Marshal([]string{name})
+	err := c.do(ctx, http.MethodPost, "/universe/ids/", bytes.NewBuffer(body), &idData)
+	if err != nil {
+		return 0, err
+	}
+	if len(idData.Constellations) == 0 {
+		return 0, fmt.Errorf("constellation not found: %s", name)
+	}
+	return idData.Constellations[0].ID, nil
+}
+
+// GetRegionID resolves a region name to its ID.
+func (c *ESIClient) GetRegionID(ctx context.Context, name string) (int, error) {
+	var idData esiLocationIDResult
+	body, _ := json.Marshal([]string{name})
+	err := c.do(ctx, http.MethodPost, "/universe/ids/", bytes.NewBuffer(body), &idData)
+	if err != nil {
+		return 0, err
+	}
+	if len(idData.Regions) == 0 {
+		return 0, fmt.Errorf("region not found: %s", name)
+	}
+	return idData.Regions[0].ID, nil
+}
+
 // GetSystemDetails retrieves full system details from the local cache.
 func (c *ESIClient) GetSystemDetails(id int) (*ESISystemInfo, error) {
 	c.cacheMutex.RLock()
@@ -243,6 +350,17 @@ func (c *ESIClient) GetSystemDetails(id int) (*ESISystemInfo, error) {
 	return nil, fmt.Errorf("system ID %d not found in cache", id)
 }
 
+// GetSecurityStatus returns a system's security status from the local cache.
+// Unlike GetSystemDetails it stays quiet on a miss, as routing calls it for every jump it considers.
+func (c *ESIClient) GetSecurityStatus(id int) (float64, bool) {
+	c.cacheMutex.RLock()
+	defer c.cacheMutex.RUnlock()
+	if sys, ok := c.systemInfoCache[id]; ok {
+		return sys.SecurityStatus, true
+	}
+	return 0, false
+}
+
 /// Add this to your /internal/esi/client.go file
 
 type EsiSystemKills struct {
@@ -261,3 +379,97 @@ func (c *ESIClient) GetSystemKills(ctx context.Context) ([]EsiSystemKills, error
 	}
 	return kills, nil
 }
+
+// SetAutopilotWaypoint sets the in-game autopilot for the character the
+// access token belongs to. clearOther replaces the current route with this
+// system as the destination; otherwise the system is added as the last waypoint.
+// Needs the esi-ui.write_waypoint.v1 scope.
+func (c *ESIClient) SetAutopilotWaypoint(ctx context.Context, accessToken string, systemID int, clearOther bool) error {
+	endpoint := fmt.Sprintf("/ui/autopilot/waypoint/?add_to_beginning=false&clear_other_waypoints=%t&destination_id=%d", clearOther, systemID)
+	return c.doAuthed(ctx, http.MethodPost, endpoint, accessToken, nil, nil)
+}
+
+// GetCharacterLocation returns the solar system and ship of the character
+// the access token belongs to. Needs the esi-location.read_location.v1 and
+// esi-location.read_ship_type.v1 scopes.
+func (c *ESIClient) GetCharacterLocation(ctx context.Context, accessToken string, characterID int) (*CharacterLocation, error) {
+	var loc esiCharacterLocation
+	if err := c.doAuthed(ctx, http.MethodGet, fmt.Sprintf("/characters/%d/location/", characterID), accessToken, nil, &loc); err != nil {
+		return nil, fmt.Errorf("failed to get location: %w", err)
+	}
+	var ship esiCharacterShip
+	if err := c.doAuthed(ctx, http.MethodGet, fmt.Sprintf("/characters/%d/ship/", characterID), accessToken, nil, &ship); err != nil {
+		return nil, fmt.Errorf("failed to get ship: %w", err)
+	}
+	return &CharacterLocation{
+		SolarSystemID: loc.SolarSystemID,
+		ShipTypeID:    ship.ShipTypeID,
+		ShipName:      ship.ShipName,
+	}, nil
+}
+
+// GetTypeGroupID returns the inventory group a type belongs to, such as a ship's hull class.
+func (c *ESIClient) GetTypeGroupID(ctx context.Context, typeID int) (int, error) {
+	c.cacheMutex.RLock()
+	if id, ok := c.typeGroupCache[typeID]; ok {
+		c.cacheMutex.RUnlock()
+		return id, nil
+	}
+	c.cacheMutex.RUnlock()
+
+	var info esiTypeInfo
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/universe/types/%d/", typeID), nil, &info); err != nil {
+		return 0, err
+	}
+	c.cacheMutex.Lock()
+	c.typeGroupCache[typeID] = info.GroupID
+	c.cacheMutex.Unlock()
+	return info.GroupID, nil
+}
+
+// GetAffiliations returns the corporation and alliance of each character,
+// keyed by character ID. ESI takes up to a thousand characters per call, so
+// all but the largest lists are checked in one request.
+func (c *ESIClient) GetAffiliations(ctx context.Context, characterIDs []int) (map[int]CharacterAffiliation, error) {
+	results := make(map[int]CharacterAffiliation, len(characterIDs))
+	for start := 0; start < len(characterIDs); start += affiliationBatchSize {
+		batch := characterIDs[start:min(start+affiliationBatchSize, len(characterIDs))]
+		body, _ := json.Marshal(batch)
+		var affiliations []CharacterAffiliation
+		if err := c.do(ctx, http.MethodPost, "/characters/affiliation/", bytes.NewBuffer(body), &affiliations); err != nil {
+			return nil, fmt.Errorf("failed to get affiliations: %w", err)
+		}
+		for _, a := range affiliations {
+			results[a.CharacterID] = a
+		}
+	}
+	return results, nil
+}
+
+// GetEntityIDs resolves names to the characters, corporations and alliances
+// that carry them. A name can match one of each.
+func (c *ESIClient) GetEntityIDs(ctx context.Context, names []string) (*EntityIDs, error) {
+	ids := &EntityIDs{
+		Characters:   make(map[string]int),
+		Corporations: make(map[string]int),
+		Alliances:    make(map[string]int),
+	}
+	if len(names) == 0 {
+		return ids, nil
+	}
+	var idData esiEntityIDResult
+	body, _ := json.Marshal(names)
+	if err := c.do(ctx, http.MethodPost, "/universe/ids/", bytes.NewBuffer(body), &idData); err != nil {
+		return nil, err
+	}
+	for _, e := range idData.Characters {
+		ids.Characters[strings.ToLower(e.Name)] = e.ID
+	}
+	for _, e := range idData.Corporations {
+		ids.Corporations[strings.ToLower(e.Name)] = e.ID
+	}
+	for _, e := range idData.Alliances {
+		ids.Alliances[strings.ToLower(e.Name)] = e.ID
+	}
+	return ids, nil
+}
@@ -36,10 +36,23 @@ func FetchWingspanData(apiURL string) (*models.WingspanAPIResponse, error) {
 	return &apiResponse, nil
 }
 
+// eveScoutURL lists EVE-Scout's public connections for every hub it scans.
+const eveScoutURL = "https://api.eve-scout.com/v2/public/signatures"
+
 func FetchTheraData() ([]models.TheraConnection, error) {
+	return fetchEveScout(eveScoutURL + "?system_name=thera")
+}
+
+// FetchEveScoutData returns EVE-Scout's public connections for both Thera
+// and Turnur, for routing through either hub.
+func FetchEveScoutData() ([]models.TheraConnection, error) {
+	return fetchEveScout(eveScoutURL)
+}
+
+func fetchEveScout(url string) ([]models.TheraConnection, error) {
 	// Use a custom client with a timeout for robustness.
 	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get("https://api.eve-scout.com/v2/public/signatures?system_name=thera")
+	resp, err := client.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request to eve-scout api: %w", err)
 	}
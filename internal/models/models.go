@@ -4,11 +4,186 @@ import "time"
 
 // FrontendData is the main data structure passed to your templates.
 type FrontendData struct {
-	Connections   []ConnectionInfo
-	Leaderboard   []LeaderboardEntry
-	FeedbackURL   string
-	Path          []PathStep
-	CharacterName string
+	Connections    []ConnectionInfo
+	Leaderboard    []LeaderboardEntry
+	FeedbackURL    string
+	Path           []PathStep
+	Routes         []RouteOption // alternative routes, cheapest first, when more than one was asked for
+	Alternatives   int
+	SelectedRoute  int // 1-based index into Routes of the route Path holds
+	NoRoute        bool
+	StartSystem    string
+	EndSystem      string
+	Nearest        string   // nearest-of-set preset used instead of EndSystem
+	NearestSystem  string   // the destination the preset picked
+	Via            []string // intermediate waypoints, in the order they are visited
+	OptimiseOrder  bool
+	RouteProfile   string
+	ShipClass      string
+	LocationError  string // why "use my current location" couldn't fill the form
+	IgnoreEOL      bool
+	IgnoreCritical bool
+	MinHoursLeft   int
+	MaxAgeHours    int
+	Avoid          AvoidList // avoids entered for this request only
+	SavedAvoid     AvoidList // the character's persistent avoid list
+	UseSavedAvoid  bool
+	AvoidNotice    string
+	OrderNotice    string   // why "optimise order" left the waypoints as entered
+	RouteRisk      float64  // total risk along Path
+	Hotspots       []string // systems on Path flagged as hotspots
+	RouteText      string   // Path as a numbered jump list, for copying
+	RouteChat      string   // Path as in-game chat showinfo links
+	RouteSummary   string   // Path as one line for fleet comms
+	ExportURL      string   // GET URL that reproduces this route, for the export links
+	ReachFrom      string
+	ReachJumps     int
+	Reach          []ReachGroup  // systems within ReachJumps of ReachFrom, nearest first
+	CapitalJumps   []CapitalJump // start first, then each jump's landing system
+	CapitalTotalLY float64
+	JumpRange      float64
+	JumpMode       string
+	CapitalError   string
+	Bridges        []JumpBridge
+	UseBridges     bool
+	IsAdmin        bool
+	Watches        []RouteWatch      // the character's watched routes
+	WatchNotices   []RouteWatch      // watched routes with a change the character hasn't seen
+	Characters     []LinkedCharacter // characters on the pilot's account, for the switcher
+	AccessList     AccessList
+	CharacterName  string
+}
+
+// CapitalJump is one system in a capital jump plan.
+type CapitalJump struct {
+	SystemName     string
+	SecurityStatus float64
+	SecurityClass  string
+	LightYears     float64 // length of the jump that lands here; 0 for the start
+}
+
+// KillBaseline is each system's long-run average of ship and pod kills per
+// hour, kept up to date by the kill updater.
+type KillBaseline struct {
+	UpdatedAt time.Time       `json:"updated_at"`
+	Samples   int             `json:"samples"` // hourly samples folded in so far
+	Systems   map[int]float64 `json:"systems"`
+}
+
+// JumpBridge is one player-owned jump bridge, as saved to disk.
+type JumpBridge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	FromID int    `json:"from_id"`
+	ToID   int    `json:"to_id"`
+}
+
+// RouteWatch is a route a character has asked to hear about when it gets
+// shorter or a hole on it collapses. It is saved to disk and re-planned in
+// the background whenever the wormhole overlay changes.
+type RouteWatch struct {
+	ID          string    `json:"id"`
+	CharacterID int       `json:"character_id"`
+	Start       string    `json:"start"`
+	End         string    `json:"end"`
+	StartID     int       `json:"start_id"`
+	EndID       int       `json:"end_id"`
+	Profile     string    `json:"profile"`
+	ShipClass   string    `json:"ship_class"`
+	UseBridges  bool      `json:"use_bridges"`
+	Avoid       AvoidList `json:"avoid"`
+	AvoidIDs    *AvoidIDs `json:"avoid_ids,omitempty"` // Avoid resolved when the watch was added; nil on older watches
+	Webhook     string    `json:"webhook,omitempty"`   // Discord webhook to post changes to
+	Systems     []int     `json:"systems"`             // the route as last planned, nil if there was none
+	Version     string    `json:"version"`             // overlay version Systems was planned against
+	Notice      string    `json:"notice,omitempty"`    // latest change, cleared once seen
+	NoticeAt    time.Time `json:"notice_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Jumps returns the number of jumps on the watched route, or -1 if there is no route.
+func (w RouteWatch) Jumps() int {
+	return len(w.Systems) - 1
+}
+
+// LinkedCharacter is one of the characters a pilot has linked to their
+// account through SSO. Membership is re-checked against ESI from time to time.
+type LinkedCharacter struct {
+	ID              int       `json:"id"`
+	Name            string    `json:"name"`
+	CorporationID   int       `json:"corporation_id"`
+	CorporationName string    `json:"corporation_name"`
+	AllianceID      int       `json:"alliance_id,omitempty"`
+	AllianceName    string    `json:"alliance_name,omitempty"`
+	Member          bool      `json:"member"` // whether the access list lets the character use the hub
+	CheckedAt       time.Time `json:"checked_at"`
+	LinkedAt        time.Time `json:"linked_at"`
+	Active          bool      `json:"-"` // the character the session is acting as
+}
+
+// AccessList decides who may use the hub. A deny beats an allow, and anyone
+// not allowed is turned away.
+type AccessList struct {
+	Allow AccessRules `json:"allow"`
+	Deny  AccessRules `json:"deny"`
+}
+
+// AccessRules lists characters, corporations and alliances by ID, with the
+// names kept so the list can be shown and edited.
+type AccessRules struct {
+	Characters   []AccessEntry `json:"characters"`
+	Corporations []AccessEntry `json:"corporations"`
+	Alliances    []AccessEntry `json:"alliances"`
+}
+
+type AccessEntry struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Allows reports whether a character in the given corporation and alliance
+// may use the hub. allianceID is 0 for a corporation in no alliance.
+func (a AccessList) Allows(characterID, corporationID, allianceID int) bool {
+	if a.Deny.Matches(characterID, corporationID, allianceID) {
+		return false
+	}
+	return a.Allow.Matches(characterID, corporationID, allianceID)
+}
+
+// Matches reports whether any of the rules names the character, its
+// corporation or its alliance.
+func (r AccessRules) Matches(characterID, corporationID, allianceID int) bool {
+	for _, e := range r.Characters {
+		if e.ID == characterID {
+			return true
+		}
+	}
+	for _, e := range r.Corporations {
+		if e.ID == corporationID {
+			return true
+		}
+	}
+	for _, e := range r.Alliances {
+		if allianceID != 0 && e.ID == allianceID {
+			return true
+		}
+	}
+	return false
+}
+
+// AvoidIDs is an AvoidList resolved to IDs, so it can be routed around again
+// without looking the names up.
+type AvoidIDs struct {
+	Systems        []int `json:"systems"`
+	Constellations []int `json:"constellations"`
+	Regions        []int `json:"regions"`
+}
+
+// AvoidList holds the names of places a route should stay out of.
+type AvoidList struct {
+	Systems        []string
+	Constellations []string
+	Regions        []string
 }
 
 type LeaderboardEntry struct {
@@ -94,12 +269,63 @@ type TheraConnection struct {
 
 // PathStep represents one step in the calculated route.
 type PathStep struct {
+	SystemID       int
 	SystemName     string
 	JumpType       string
 	SecurityStatus float64 // ADD THIS
 	SecurityClass  string
 	ShipKills      int // ADD THIS
+	PodKills       int
 	NpcKills       int // ADD THIS
+	Risk           float64
+	Hotspot        bool   // risk at or above routing.HotspotRisk
+	Note           string // travel rules for Pochven, Zarzakh or J-space; see routing.Space
+	Leg            int    // 1-based leg of a multi-waypoint route
+	Waypoint       bool   // true where a multi-waypoint route stops at an intermediate waypoint
+
+	// Set when the jump into this system is through a wormhole.
+	Source        string // "Wingspan" or "EVE-Scout"
+	FromSignature string // signature in the previous system
+	ToSignature   string // signature in this system
+	WormholeType  string
+	Scout         string
+	LastModified  string
+	Life          string
+	Mass          string
+	HoursLeft     int // -1 when unknown
+}
+
+// ReachGroup holds the systems a given number of jumps from the reach origin.
+type ReachGroup struct {
+	Jumps   int
+	Classes []ReachClass
+}
+
+// ReachClass holds the systems of one security class within a ReachGroup.
+type ReachClass struct {
+	SecurityClass string
+	Systems       []ReachSystem
+}
+
+// ReachSystem is one system listed on the reachability page.
+type ReachSystem struct {
+	Name           string
+	SecurityStatus float64
+	TradeHub       bool
+	Entry          string // "thera" or "turnur" when the system connects to either
+}
+
+// RouteOption is one of several alternative routes, with the totals used to compare them.
+type RouteOption struct {
+	Path          []PathStep
+	Jumps         int
+	WormholeJumps int
+	ShipKills     int
+	PodKills      int
+	NpcKills      int
+	Risk          float64
+	Hotspots      int
+	SelectURL     string // results page with this route selected
 }
 
 type ESISystemInfo struct {
@@ -0,0 +1,55 @@
+package models
+
+import "testing"
+
+func TestAccessListAllows(t *testing.T) {
+	const (
+		pilot    = 90000001
+		corp     = 98000001
+		alliance = 99000001
+	)
+	entries := func(ids ...int) []AccessEntry {
+		var e []AccessEntry
+		for _, id := range ids {
+			e = append(e, AccessEntry{ID: id})
+		}
+		return e
+	}
+
+	tests := []struct {
+		name                 string
+		list                 AccessList
+		char, corp, alliance int
+		want                 bool
+	}{
+		{"empty list", AccessList{}, pilot, corp, alliance, false},
+		{"allowed character", AccessList{Allow: AccessRules{Characters: entries(pilot)}}, pilot, corp, alliance, true},
+		{"allowed corporation", AccessList{Allow: AccessRules{Corporations: entries(corp)}}, pilot, corp, alliance, true},
+		{"allowed alliance", AccessList{Allow: AccessRules{Alliances: entries(alliance)}}, pilot, corp, alliance, true},
+		{"other corporation", AccessList{Allow: AccessRules{Corporations: entries(corp)}}, pilot, corp + 1, alliance, false},
+		{"no alliance never matches", AccessList{Allow: AccessRules{Alliances: entries(0)}}, pilot, corp, 0, false},
+		{"denied character in allowed alliance", AccessList{
+			Allow: AccessRules{Alliances: entries(alliance)},
+			Deny:  AccessRules{Characters: entries(pilot)},
+		}, pilot, corp, alliance, false},
+		{"denied corporation in allowed alliance", AccessList{
+			Allow: AccessRules{Alliances: entries(alliance)},
+			Deny:  AccessRules{Corporations: entries(corp)},
+		}, pilot, corp, alliance, false},
+		{"denied alliance with allowed character", AccessList{
+			Allow: AccessRules{Characters: entries(pilot)},
+			Deny:  AccessRules{Alliances: entries(alliance)},
+		}, pilot, corp, alliance, false},
+		{"deny for someone else", AccessList{
+			Allow: AccessRules{Alliances: entries(alliance)},
+			Deny:  AccessRules{Characters: entries(pilot + 1)},
+		}, pilot, corp, alliance, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.list.Allows(tt.char, tt.corp, tt.alliance); got != tt.want {
+				t.Errorf("Allows(%d, %d, %d) = %t, want %t", tt.char, tt.corp, tt.alliance, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+// internal/routing/astar.go
+package routing
+
+// astar runs A* from src to dst, ordering the queue by cost so far plus the
+// landmark estimate of the cost left. The heuristic is consistent, so each
+// system is final the first time it is popped, just as with Dijkstra.
+func (s *Snapshot) astar(srcIdx, dstIdx int32, opts RouteOptions, ban *bans) *SearchResult {
+	h := s.heuristicTo(dstIdx, opts)
+	b := getBuffers(s.size())
+	result := &SearchResult{snap: s, src: srcIdx, buf: b}
+	b.dist[srcIdx] = 0
+	b.pq.push(item{node: srcIdx, dist: h.estimate(srcIdx)})
+
+	for len(b.pq) > 0 {
+		u := b.pq.pop().node
+		if b.visited[u] {
+			continue
+		}
+		b.visited[u] = true
+		if u == dstIdx {
+			break
+		}
+		if s.noTransit(u, srcIdx) {
+			continue
+		}
+		for _, edges := range s.neighbors(u) {
+			for _, e := range edges {
+				if b.visited[e.to] || !s.usable(u, e, dstIdx, opts, ban) {
+					continue
+				}
+				if nd := b.dist[u] + opts.cost(e); nd < b.dist[e.to] {
+					b.dist[e.to] = nd
+					b.prev[e.to] = u
+					b.pq.push(item{node: e.to, dist: nd + h.estimate(e.to)})
+				}
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,40 @@
+// internal/routing/avoid.go
+package routing
+
+// Avoidance is a set of systems, constellations and regions a route must not pass through.
+type Avoidance struct {
+	Systems        map[int]bool
+	Constellations map[int]bool
+	Regions        map[int]bool
+}
+
+// NewAvoidance returns an empty Avoidance ready to be filled.
+func NewAvoidance() *Avoidance {
+	return &Avoidance{
+		Systems:        make(map[int]bool),
+		Constellations: make(map[int]bool),
+		Regions:        make(map[int]bool),
+	}
+}
+
+// Empty reports whether nothing is being avoided.
+func (a *Avoidance) Empty() bool {
+	return a == nil || len(a.Systems)+len(a.Constellations)+len(a.Regions) == 0
+}
+
+// Avoids reports whether the route must stay out of the given system.
+func (g *Graph) Avoids(a *Avoidance, systemID int) bool {
+	if a.Empty() {
+		return false
+	}
+	if a.Systems[systemID] {
+		return true
+	}
+	if id, ok := g.constellation[systemID]; ok && a.Constellations[id] {
+		return true
+	}
+	if id, ok := g.region[systemID]; ok && a.Regions[id] {
+		return true
+	}
+	return false
+}
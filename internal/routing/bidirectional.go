@@ -0,0 +1,111 @@
+// internal/routing/bidirectional.go
+package routing
+
+// bidirectional runs Dijkstra forwards from src and backwards from dst at
+// the same time, always growing whichever side has the cheaper frontier,
+// and stops once no meeting point could beat the best route seen so far.
+//
+// Jumps are costed on the system they land in, so the two directions of a
+// connection can cost different amounts. The backward search therefore
+// prices each edge as the forward jump into the system it is expanding from.
+// Every connection is stored in both directions, so the edges out of a
+// system are also the edges into it. Neither side may meet the other in a
+// system routes can't pass through, such as Zarzakh.
+func (s *Snapshot) bidirectional(srcIdx, dstIdx int32, opts RouteOptions, ban *bans) *SearchResult {
+	fwd, bwd := getBuffers(s.size()), getBuffers(s.size())
+	defer bufferPool.Put(bwd)
+	result := &SearchResult{snap: s, src: srcIdx, buf: fwd}
+
+	fwd.dist[srcIdx] = 0
+	fwd.pq.push(item{node: srcIdx, dist: 0})
+	bwd.dist[dstIdx] = 0
+	bwd.pq.push(item{node: dstIdx, dist: 0})
+
+	// best is the cheapest complete route seen, made of the forward path to
+	// meetFrom, the jump to meetTo and the backward path on from meetTo.
+	best := unreached
+	meetFrom, meetTo := int32(-1), int32(-1)
+
+	for len(fwd.pq) > 0 && len(bwd.pq) > 0 {
+		if fwd.pq[0].dist+bwd.pq[0].dist >= best {
+			break
+		}
+
+		if fwd.pq[0].dist <= bwd.pq[0].dist {
+			u := fwd.pq.pop().node
+			if fwd.visited[u] {
+				continue
+			}
+			fwd.visited[u] = true
+			if s.noTransit(u, srcIdx) {
+				continue
+			}
+			for _, edges := range s.neighbors(u) {
+				for _, e := range edges {
+					if fwd.visited[e.to] || !s.usable(u, e, dstIdx, opts, ban) {
+						continue
+					}
+					nd := fwd.dist[u] + opts.cost(e)
+					if nd < fwd.dist[e.to] {
+						fwd.dist[e.to] = nd
+						fwd.prev[e.to] = u
+						fwd.pq.push(item{node: e.to, dist: nd})
+					}
+					if bwd.dist[e.to] != unreached && nd+bwd.dist[e.to] < best && !s.noTransit(e.to, dstIdx) {
+						best, meetFrom, meetTo = nd+bwd.dist[e.to], u, e.to
+					}
+				}
+			}
+			continue
+		}
+
+		v := bwd.pq.pop().node
+		if bwd.visited[v] {
+			continue
+		}
+		bwd.visited[v] = true
+		if s.noTransit(v, dstIdx) {
+			continue
+		}
+		vID := s.idOf(v)
+		for _, edges := range s.neighbors(v) {
+			for _, e := range edges {
+				// e leads from v to u; the route travels it from u to v.
+				u := e.to
+				if bwd.visited[u] || !opts.allows(e) {
+					continue
+				}
+				if u != srcIdx && s.Avoids(opts.Avoid, e.To) {
+					continue
+				}
+				if ban != nil && (ban.nodes[e.To] || ban.edges[[2]int{e.To, vID}]) {
+					continue
+				}
+				jump := Edge{To: vID, Weight: e.Weight, Kind: e.Kind, Link: e.Link, to: v}
+				nd := bwd.dist[v] + opts.cost(jump)
+				if nd < bwd.dist[u] {
+					bwd.dist[u] = nd
+					bwd.prev[u] = v // next system towards dst
+					bwd.pq.push(item{node: u, dist: nd})
+				}
+				if fwd.dist[u] != unreached && fwd.dist[u]+nd < best && !s.noTransit(u, srcIdx) {
+					best, meetFrom, meetTo = fwd.dist[u]+nd, u, v
+				}
+			}
+		}
+	}
+
+	if meetFrom < 0 {
+		return result
+	}
+	// Graft the backward half onto the forward tree so the result reads like
+	// a single search from src.
+	fwd.prev[meetTo] = meetFrom
+	for x := meetTo; x != -1; x = bwd.prev[x] {
+		fwd.dist[x] = best - bwd.dist[x]
+		if next := bwd.prev[x]; next != -1 {
+			fwd.prev[next] = x
+		}
+	}
+	return result
+}
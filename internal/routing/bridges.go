@@ -0,0 +1,68 @@
+// internal/routing/bridges.go
+package routing
+
+import (
+	"fmt"
+	"strings"
+)
+
+// bridgeSeparators are the arrows accepted between the two ends of a jump
+// bridge. The game's structure browser uses "»".
+var bridgeSeparators = []string{"»", "<->", "-->", "->"}
+
+// BridgeLine is one jump bridge as named in a pasted list.
+type BridgeLine struct {
+	From, To string
+}
+
+// ParseBridgeList reads jump bridges in the usual pasted form, one per line:
+//
+//	1DQ1-A » 8QT-H4 - Keepstar Express
+//
+// Anything after " - " on the right is the structure's name and is dropped.
+// Blank lines and lines starting with # are skipped.
+func ParseBridgeList(text string) ([]BridgeLine, error) {
+	var bridges []BridgeLine
+	for n, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var from, to string
+		found := false
+		for _, sep := range bridgeSeparators {
+			if from, to, found = strings.Cut(line, sep); found {
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("line %d: expected \"from » to\", got %q", n+1, line)
+		}
+		to, _, _ = strings.Cut(to, " - ")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if from == "" || to == "" {
+			return nil, fmt.Errorf("line %d: missing system name in %q", n+1, line)
+		}
+		bridges = append(bridges, BridgeLine{From: from, To: to})
+	}
+	return bridges, nil
+}
+
+// UpdateBridges replaces the jump bridges in the overlay, keeping the
+// current wormholes. Each link's Kind is set to KindBridge. It returns false
+// when nothing changed.
+func (g *Graph) UpdateBridges(links []WHLink) bool {
+	return g.publish(func(current []WHLink) []WHLink {
+		var next []WHLink
+		for _, l := range current {
+			if l.Kind != KindBridge {
+				next = append(next, l)
+			}
+		}
+		for _, l := range links {
+			l.Kind = KindBridge
+			next = append(next, l)
+		}
+		return next
+	})
+}
@@ -0,0 +1,67 @@
+package routing
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseBridgeList(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []BridgeLine
+		wantErr bool
+	}{
+		{"game format with structure name", "1DQ1-A » 8QT-H4 - Keepstar Express", []BridgeLine{{"1DQ1-A", "8QT-H4"}}, false},
+		{"ascii arrows", "X-7OMU -> RF-GGF\nRF-GGF --> 5ZXX-K\nJ-LPX7 <-> 1DQ1-A", []BridgeLine{{"X-7OMU", "RF-GGF"}, {"RF-GGF", "5ZXX-K"}, {"J-LPX7", "1DQ1-A"}}, false},
+		{"blank lines and comments", "\n# Delve\n  1DQ1-A » 8QT-H4  \n\n", []BridgeLine{{"1DQ1-A", "8QT-H4"}}, false},
+		{"empty", "", nil, false},
+		{"no arrow", "1DQ1-A 8QT-H4", nil, true},
+		{"missing end", "1DQ1-A » ", nil, true},
+		{"missing start", "» 8QT-H4", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBridgeList(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %t", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateBridgesKeepsWormholes(t *testing.T) {
+	g := loadTinyGraph(t, [][2]int{{1, 2}, {2, 3}, {3, 4}})
+	g.UpdateWormholes([]WHLink{{From: 1, To: 4, Cost: 1}})
+	if !g.UpdateBridges([]WHLink{{From: 2, To: 4, Cost: 1}}) {
+		t.Fatal("UpdateBridges reported no change")
+	}
+	if g.UpdateBridges([]WHLink{{From: 2, To: 4, Cost: 1}}) {
+		t.Error("UpdateBridges reported a change for the same bridges")
+	}
+
+	snap := g.Snapshot()
+	var kinds []EdgeKind
+	for _, l := range snap.Overlay().Links() {
+		kinds = append(kinds, l.Kind)
+	}
+	slices.Sort(kinds)
+	if want := []EdgeKind{KindBridge, KindWormhole}; !slices.Equal(kinds, want) {
+		t.Errorf("overlay kinds %v, want %v", kinds, want)
+	}
+
+	// Bridges are opt-in and closed to capitals.
+	with := RouteOptions{Bridges: true}
+	if _, ok := snap.Jump(2, 4, with); !ok {
+		t.Error("bridge 2→4 not usable with bridges on")
+	}
+	if _, ok := snap.Jump(2, 4, RouteOptions{}); ok {
+		t.Error("bridge 2→4 usable with bridges off")
+	}
+	if _, ok := snap.Jump(2, 4, RouteOptions{Bridges: true, Ship: ShipCapital}); ok {
+		t.Error("bridge 2→4 usable by a capital")
+	}
+}
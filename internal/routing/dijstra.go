@@ -1,66 +1,230 @@
 // internal/routing/dijkstra.go
 package routing
 
-import "container/heap"
+import "sync"
 
-type item struct{ node, dist int }
+const unreached = int(1e9)
+
+// item is a queue entry. dist is its priority: the cost so far, plus the
+// heuristic estimate of the rest under A*.
+type item struct {
+	node int32
+	dist int
+}
+
+// pqueue is a binary min-heap of items. It is typed rather than built on
+// container/heap so that pushing doesn't box every item into an interface.
 type pqueue []item
 
-func (pq pqueue) Len() int            { return len(pq) }
-func (pq pqueue) Less(i, j int) bool  { return pq[i].dist < pq[j].dist }
-func (pq pqueue) Swap(i, j int)       { pq[i], pq[j] = pq[j], pq[i] }
-func (pq *pqueue) Push(x interface{}) { *pq = append(*pq, x.(item)) }
-func (pq *pqueue) Pop() interface{} {
-	old := *pq
-	n := len(old)
-	it := old[n-1]
-	*pq = old[:n-1]
-	return it
+func (pq *pqueue) push(it item) {
+	h := append(*pq, it)
+	i := len(h) - 1
+	for i > 0 {
+		parent := (i - 1) / 2
+		if h[parent].dist <= h[i].dist {
+			break
+		}
+		h[parent], h[i] = h[i], h[parent]
+		i = parent
+	}
+	*pq = h
+}
+
+func (pq *pqueue) pop() item {
+	h := *pq
+	top := h[0]
+	last := len(h) - 1
+	h[0] = h[last]
+	h = h[:last]
+	i := 0
+	for {
+		smallest, l, r := i, 2*i+1, 2*i+2
+		if l < len(h) && h[l].dist < h[smallest].dist {
+			smallest = l
+		}
+		if r < len(h) && h[r].dist < h[smallest].dist {
+			smallest = r
+		}
+		if smallest == i {
+			break
+		}
+		h[i], h[smallest] = h[smallest], h[i]
+		i = smallest
+	}
+	*pq = h
+	return top
+}
+
+// searchBuffers is the per-search state, indexed by dense system index.
+// Buffers are pooled so repeated searches don't allocate.
+type searchBuffers struct {
+	dist    []int
+	prev    []int32
+	visited []bool
+	pq      pqueue
 }
 
-func (g *Graph) neighbors(u int) []Edge {
-	out := g.staticAdj[u]
-	if ds, ok := g.dynamicAdj[u]; ok {
-		out = append(out, ds...)
+var bufferPool = sync.Pool{New: func() any { return new(searchBuffers) }}
+
+func getBuffers(n int) *searchBuffers {
+	b := bufferPool.Get().(*searchBuffers)
+	if cap(b.dist) < n {
+		b.dist = make([]int, n)
+		b.prev = make([]int32, n)
+		b.visited = make([]bool, n)
+	}
+	b.dist, b.prev, b.visited = b.dist[:n], b.prev[:n], b.visited[:n]
+	for i := range b.dist {
+		b.dist[i] = unreached
+		b.prev[i] = -1
+		b.visited[i] = false
 	}
-	return out
+	b.pq = b.pq[:0]
+	return b
 }
 
-func (g *Graph) ShortestPath(src, dst int) (dist map[int]int, prev map[int]int) {
-	const INF = int(1e9)
-	dist = make(map[int]int)
-	prev = make(map[int]int)
-	pq := &pqueue{}
-	heap.Init(pq)
+// SearchResult is the shortest-path tree left by a search. Call Release
+// once finished with it so its buffers can be reused.
+type SearchResult struct {
+	snap *Snapshot
+	src  int32
+	buf  *searchBuffers
+}
 
-	// Seed
-	dist[src] = 0
-	heap.Push(pq, item{node: src, dist: 0})
+// Dist returns the cost of the cheapest route to a system, and false if the
+// search never reached it.
+func (r *SearchResult) Dist(systemID int) (int, bool) {
+	if r.buf == nil {
+		return 0, false
+	}
+	idx, ok := r.snap.indexOf(systemID)
+	if !ok || r.buf.dist[idx] == unreached {
+		return 0, false
+	}
+	return r.buf.dist[idx], true
+}
 
-	visited := make(map[int]bool)
+// PathTo returns the systems from the search's source to dst in travel
+// order, or nil if dst was never reached.
+func (r *SearchResult) PathTo(dst int) []int {
+	if r.buf == nil {
+		return nil
+	}
+	idx, ok := r.snap.indexOf(dst)
+	if !ok || r.buf.dist[idx] == unreached {
+		return nil
+	}
+	var path []int
+	for current := idx; current != -1; current = r.buf.prev[current] {
+		path = append(path, r.snap.idOf(current))
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
 
-	for pq.Len() > 0 {
-		it := heap.Pop(pq).(item)
+// Release hands the result's buffers back for reuse. The result must not be used afterwards.
+func (r *SearchResult) Release() {
+	if r.buf != nil {
+		bufferPool.Put(r.buf)
+		r.buf = nil
+	}
+}
+
+// ShortestPath runs Dijkstra from src towards dst, costing each jump with opts.
+// Pass a dst that isn't a system, such as -1, to search the whole graph.
+func (s *Snapshot) ShortestPath(src, dst int, opts RouteOptions) *SearchResult {
+	return s.search(src, dst, opts, nil)
+}
+
+// Path returns the cheapest route from src to dst as a list of systems, or nil if there is none.
+func (s *Snapshot) Path(src, dst int, opts RouteOptions) []int {
+	result := s.ShortestPath(src, dst, opts)
+	defer result.Release()
+	return result.PathTo(dst)
+}
+
+// bans removes systems and individual jumps from a search. Yen's algorithm
+// uses it to force each spur path off the routes already found.
+type bans struct {
+	nodes map[int]bool
+	edges map[[2]int]bool
+}
+
+// search finds the cheapest route from src to dst with the algorithm opts
+// selects. Searches without a destination always run Dijkstra.
+func (s *Snapshot) search(src, dst int, opts RouteOptions, ban *bans) *SearchResult {
+	srcIdx, ok := s.indexOf(src)
+	if !ok {
+		return &SearchResult{snap: s}
+	}
+	dstIdx, ok := s.indexOf(dst)
+	if !ok {
+		dstIdx = -1
+	}
+	switch {
+	case dstIdx < 0 || dstIdx == srcIdx:
+		return s.dijkstra(srcIdx, dstIdx, opts, ban)
+	case opts.Algorithm == AlgorithmBidirectional:
+		return s.bidirectional(srcIdx, dstIdx, opts, ban)
+	case opts.Algorithm == AlgorithmAStar:
+		return s.astar(srcIdx, dstIdx, opts, ban)
+	}
+	return s.dijkstra(srcIdx, dstIdx, opts, ban)
+}
+
+// usable reports whether a forward search may take e out of the system at u.
+// The destination is always allowed, even if it is on the avoid list.
+func (s *Snapshot) usable(u int32, e Edge, dst int32, opts RouteOptions, ban *bans) bool {
+	if !opts.allows(e) {
+		return false
+	}
+	if e.to != dst && s.Avoids(opts.Avoid, e.To) {
+		return false
+	}
+	return ban == nil || !(ban.nodes[e.To] || ban.edges[[2]int{s.idOf(u), e.To}])
+}
+
+func (s *Snapshot) dijkstra(srcIdx, dstIdx int32, opts RouteOptions, ban *bans) *SearchResult {
+	result, _ := s.dijkstraUntil(srcIdx, dstIdx, opts, ban, func(u int32) bool { return u == dstIdx })
+	return result
+}
+
+// dijkstraUntil runs Dijkstra from src until it settles a system goal
+// accepts, and returns that system's dense index, or -1 if it found none.
+// dstIdx is only used to let the destination through the avoid list.
+func (s *Snapshot) dijkstraUntil(srcIdx, dstIdx int32, opts RouteOptions, ban *bans, goal func(u int32) bool) (*SearchResult, int32) {
+	b := getBuffers(s.size())
+	result := &SearchResult{snap: s, src: srcIdx, buf: b}
+	b.dist[srcIdx] = 0
+	b.pq.push(item{node: srcIdx, dist: 0})
+
+	for len(b.pq) > 0 {
+		it := b.pq.pop()
 		u := it.node
-		if visited[u] {
+		if b.visited[u] {
 			continue
 		}
-		visited[u] = true
-		if u == dst {
-			break
+		b.visited[u] = true
+		if goal(u) {
+			return result, u
 		}
-		for _, e := range g.neighbors(u) {
-			nd := dist[u] + e.Weight
-			d, ok := dist[e.To]
-			if !ok {
-				d = INF
-			}
-			if nd < d {
-				dist[e.To] = nd
-				prev[e.To] = u
-				heap.Push(pq, item{node: e.To, dist: nd})
+		if s.noTransit(u, srcIdx) {
+			continue
+		}
+		for _, edges := range s.neighbors(u) {
+			for _, e := range edges {
+				if b.visited[e.to] || !s.usable(u, e, dstIdx, opts, ban) {
+					continue
+				}
+				if nd := b.dist[u] + opts.cost(e); nd < b.dist[e.to] {
+					b.dist[e.to] = nd
+					b.prev[e.to] = u
+					b.pq.push(item{node: e.to, dist: nd})
+				}
 			}
 		}
 	}
-	return
+	return result, -1
 }
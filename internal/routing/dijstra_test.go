@@ -0,0 +1,124 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"testing"
+)
+
+const (
+	jitaID = 30000142 // The Forge
+	oneDQ  = 30004759 // 1DQ1-A, Delve
+)
+
+// loadTestGraph loads the stargate map shipped at the repository root.
+func loadTestGraph(tb testing.TB) *Graph {
+	tb.Helper()
+	g := NewGraph()
+	if err := g.LoadCSV("../../mapSolarSystemJumps.csv"); err != nil {
+		tb.Fatalf("LoadCSV: %v", err)
+	}
+	return g
+}
+
+// BenchmarkShortestPathCrossRegion routes from Jita across several regions to
+// Delve, the kind of request that explores most of the map.
+func BenchmarkShortestPathCrossRegion(b *testing.B) {
+	snap := loadTestGraph(b).Snapshot()
+	opts := RouteOptions{Profile: ProfileShortest}
+	if len(snap.Path(jitaID, oneDQ, opts)) == 0 {
+		b.Fatal("no route from Jita to 1DQ1-A")
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		snap.Path(jitaID, oneDQ, opts)
+	}
+}
+
+// testSecurity reads system security from the systems.json cache at the
+// repository root.
+func testSecurity(t *testing.T) SecurityFunc {
+	t.Helper()
+	data, err := os.ReadFile("../../systems.json")
+	if err != nil {
+		t.Fatalf("read systems.json: %v", err)
+	}
+	var systems map[int]struct {
+		Security float64 `json:"security_status"`
+	}
+	if err := json.Unmarshal(data, &systems); err != nil {
+		t.Fatalf("decode systems.json: %v", err)
+	}
+	return func(id int) (float64, bool) {
+		s, ok := systems[id]
+		return s.Security, ok
+	}
+}
+
+// TestAlgorithmsAgree checks that bidirectional Dijkstra and A* find routes
+// exactly as cheap as plain Dijkstra, for every profile, with and without
+// wormholes, avoid lists and Zarzakh at either end.
+func TestAlgorithmsAgree(t *testing.T) {
+	g := loadTestGraph(t)
+	security := testSecurity(t)
+	risk := func(id int) float64 { return float64(id % 7) }
+
+	plain := g.Snapshot()
+	g.UpdateWormholes([]WHLink{
+		{From: jitaID, To: TheraSystemID, Cost: 1},
+		{From: TheraSystemID, To: oneDQ, Cost: 1},
+		{From: TheraSystemID, To: 31000001, Cost: 1},
+		{From: 31000001, To: 30002187, Cost: 1}, // Amarr
+		{From: 30003841, To: 30000001, Cost: 1}, // behind Zarzakh, to Tanoo
+	})
+	holes := g.Snapshot()
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	type pair struct{ src, dst int }
+	pairs := []pair{
+		{jitaID, oneDQ},
+		{ZarzakhSystemID, jitaID},
+		{oneDQ, ZarzakhSystemID},
+		{30001041, 30003841}, // two of Zarzakh's neighbours
+	}
+	for len(pairs) < 40 {
+		pairs = append(pairs, pair{g.ids[rng.IntN(len(g.ids))], g.ids[rng.IntN(len(g.ids))]})
+	}
+
+	avoid := NewAvoidance()
+	avoid.Systems[30002768] = true        // Uedama
+	avoid.Constellations[20000020] = true // Kimotoro, around Jita
+	avoid.Regions[10000060] = true        // Delve
+	avoids := map[string]*Avoidance{"none": nil, "avoid": avoid}
+
+	snaps := map[string]*Snapshot{"gates": plain, "wormholes": holes}
+	for snapName, snap := range snaps {
+		for avoidName, a := range avoids {
+			for _, p := range Profiles {
+				name := fmt.Sprintf("%s/%s/%s", snapName, avoidName, p.Value)
+				t.Run(name, func(t *testing.T) {
+					for _, pr := range pairs {
+						opts := RouteOptions{Profile: p.Value, Security: security, Risk: risk, Avoid: a}
+						opts.Algorithm = AlgorithmDijkstra
+						want := snap.Path(pr.src, pr.dst, opts)
+						wantCost := snap.PathCost(want, opts)
+						for _, alg := range []Algorithm{AlgorithmBidirectional, AlgorithmAStar} {
+							opts.Algorithm = alg
+							got := snap.Path(pr.src, pr.dst, opts)
+							if (got == nil) != (want == nil) {
+								t.Errorf("%s %d→%d: found=%t, Dijkstra found=%t", alg, pr.src, pr.dst, got != nil, want != nil)
+								continue
+							}
+							if cost := snap.PathCost(got, opts); cost != wantCost {
+								t.Errorf("%s %d→%d: cost %d, Dijkstra cost %d", alg, pr.src, pr.dst, cost, wantCost)
+							}
+						}
+					}
+				})
+			}
+		}
+	}
+}
@@ -4,24 +4,66 @@ import (
 	"encoding/csv"
 	"io"
 	"os"
+	"slices"
 	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// EdgeKind says what sort of connection an edge is.
+type EdgeKind string
+
+const (
+	KindStargate EdgeKind = "stargate"
+	KindWormhole EdgeKind = "wormhole" // a Wingspan-scanned wormhole
+	KindThera    EdgeKind = "thera"    // an EVE-Scout Thera connection
+	KindTurnur   EdgeKind = "turnur"   // an EVE-Scout Turnur connection
+	KindBridge   EdgeKind = "bridge"   // a player-owned jump bridge
 )
 
 type Edge struct {
 	To     int
 	Weight int
+	Kind   EdgeKind
+	Link   *WHLink // the wormhole behind this edge; nil for stargates
+
+	to int32 // dense index of To
 }
 
+// Graph is the static stargate map, built once at startup and never changed
+// afterwards, plus the most recently published wormhole overlay.
+//
+// Systems are re-indexed densely so searches can use slices instead of maps.
+// The stargates are stored in compressed sparse row form: the edges out of
+// the system at index i are edges[offsets[i]:offsets[i+1]].
 type Graph struct {
-	staticAdj  map[int][]Edge // from CSV stargates
-	dynamicAdj map[int][]Edge // from wormholes
+	ids     []int         // dense index -> system ID
+	index   map[int]int32 // system ID -> dense index
+	offsets []int32
+	edges   []Edge
+
+	zarzakh int32 // dense index of Zarzakh, -1 if the map doesn't have it
+
+	// landmarks[i][v] is the stargate jump count from landmark i to the
+	// system at dense index v, or -1 if it can't be reached. See landmarks.go.
+	landmarks [][]int32
+
+	constellation map[int]int // system ID -> constellation ID, from CSV
+	region        map[int]int // system ID -> region ID, from CSV
+	overlay       atomic.Pointer[Overlay]
+	publishMu     sync.Mutex // held while building and storing a new overlay
 }
 
 func NewGraph() *Graph {
-	return &Graph{
-		staticAdj:  make(map[int][]Edge),
-		dynamicAdj: make(map[int][]Edge),
+	g := &Graph{
+		index:         make(map[int]int32),
+		offsets:       []int32{0},
+		zarzakh:       -1,
+		constellation: make(map[int]int),
+		region:        make(map[int]int),
 	}
+	g.overlay.Store(g.newOverlay(nil))
+	return g
 }
 
 func (g *Graph) LoadCSV(path string) error {
@@ -32,11 +74,12 @@ func (g *Graph) LoadCSV(path string) error {
 	defer f.Close()
 
 	r := csv.NewReader(f)
-	// Optionally r.ReuseRecord = true if memory matters.
+	r.ReuseRecord = true
 	// First line is header; read and discard if present.
 	// Detect header by len and checking for non-integer fields.
 	var isHeaderChecked bool
 
+	adj := make(map[int][]int)
 	for {
 		rec, err := r.Read()
 		if err == io.EOF {
@@ -59,28 +102,78 @@ func (g *Graph) LoadCSV(path string) error {
 		if err1 != nil || err2 != nil {
 			continue
 		}
-		g.staticAdj[fromSys] = append(g.staticAdj[fromSys], Edge{To: toSys, Weight: 1})
-		g.staticAdj[toSys] = append(g.staticAdj[toSys], Edge{To: fromSys, Weight: 1})
+		if id, err := strconv.Atoi(rec[1]); err == nil {
+			g.constellation[fromSys] = id
+		}
+		if id, err := strconv.Atoi(rec[0]); err == nil {
+			g.region[fromSys] = id
+		}
+		if id, err := strconv.Atoi(rec[4]); err == nil {
+			g.constellation[toSys] = id
+		}
+		if id, err := strconv.Atoi(rec[5]); err == nil {
+			g.region[toSys] = id
+		}
+		adj[fromSys] = append(adj[fromSys], toSys)
+		adj[toSys] = append(adj[toSys], fromSys)
 	}
+	g.buildCSR(adj)
+	g.pickLandmarks()
 	return nil
 }
 
-// ... (Edge and Graph structs)
+// buildCSR indexes the stargate adjacency densely, in system ID order, and
+// drops the duplicates left by the CSV listing every gate in both directions.
+func (g *Graph) buildCSR(adj map[int][]int) {
+	g.ids = make([]int, 0, len(adj))
+	for id := range adj {
+		g.ids = append(g.ids, id)
+	}
+	slices.Sort(g.ids)
+	g.index = make(map[int]int32, len(g.ids))
+	for i, id := range g.ids {
+		g.index[id] = int32(i)
+	}
+	g.zarzakh = -1
+	if idx, ok := g.index[ZarzakhSystemID]; ok {
+		g.zarzakh = idx
+	}
 
-// Clone creates a deep copy of the static graph for a single request.
-func (g *Graph) Clone() *Graph {
-	clone := NewGraph()
-	// Copy the static connections
-	for from, edges := range g.staticAdj {
-		clone.staticAdj[from] = append(clone.staticAdj[from], edges...)
+	g.offsets = make([]int32, 1, len(g.ids)+1)
+	g.edges = g.edges[:0]
+	for _, id := range g.ids {
+		targets := adj[id]
+		slices.Sort(targets)
+		for _, to := range slices.Compact(targets) {
+			g.edges = append(g.edges, Edge{To: to, Weight: 1, Kind: KindStargate, to: g.index[to]})
+		}
+		g.offsets = append(g.offsets, int32(len(g.edges)))
 	}
-	return clone
 }
 
-// ... (your existing Graph struct and functions)
+// staticEdges returns the stargates out of the system at dense index u.
+func (g *Graph) staticEdges(u int32) []Edge {
+	if int(u) >= len(g.ids) {
+		return nil
+	}
+	return g.edges[g.offsets[u]:g.offsets[u+1]]
+}
+
+// HasStargate reports whether a stargate links two systems directly.
+func (g *Graph) HasStargate(from, to int) bool {
+	u, ok := g.index[from]
+	if !ok {
+		return false
+	}
+	for _, e := range g.staticEdges(u) {
+		if e.To == to {
+			return true
+		}
+	}
+	return false
+}
 
-// ADD THIS METHOD
 // StaticAdjacencyListSize returns the number of systems in the static graph.
 func (g *Graph) StaticAdjacencyListSize() int {
-	return len(g.staticAdj)
+	return len(g.ids)
 }
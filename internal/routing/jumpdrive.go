@@ -0,0 +1,251 @@
+// internal/routing/jumpdrive.go
+package routing
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+)
+
+const metersPerLightYear = 9_460_730_472_580_800
+
+// JumpMode selects what a capital jump plan minimises.
+type JumpMode string
+
+const (
+	JumpFewest       JumpMode = "fewest"        // fewest jumps, then fewest light-years
+	JumpLeastFatigue JumpMode = "least-fatigue" // fewest light-years in total
+)
+
+// ParseJumpMode maps a form value to a JumpMode, falling back to fewest jumps.
+func ParseJumpMode(s string) JumpMode {
+	if JumpMode(s) == JumpLeastFatigue {
+		return JumpLeastFatigue
+	}
+	return JumpFewest
+}
+
+// JumpShip is a jump drive hull with its range at max skills.
+type JumpShip struct {
+	Label string
+	Range float64 // light-years
+}
+
+// JumpShips lists the jump drive ranges the planner offers, in the order the form shows them.
+var JumpShips = []JumpShip{
+	{"Jump Freighter (10 ly)", 10},
+	{"Black Ops (8 ly)", 8},
+	{"Carrier / Dreadnought / FAX (7 ly)", 7},
+	{"Supercarrier / Titan (6 ly)", 6},
+	{"Rorqual (5 ly)", 5},
+}
+
+// jumpSystem is one system's position in space, from the SDE.
+type jumpSystem struct {
+	id       int
+	x, y, z  float64 // light-years
+	security float64
+	region   int
+}
+
+// JumpSpace is the k-space map in 3D, used to plan capital jumps.
+type JumpSpace struct {
+	systems []jumpSystem
+	index   map[int]int
+}
+
+// LoadJumpSpace reads system coordinates from the SDE's mapSolarSystems.csv.
+// Columns are found by their header names, so any export with solarSystemID,
+// regionID, x, y, z and security columns works. J-space is left out, since
+// jump drives can't be used there.
+func LoadJumpSpace(path string) (*JumpSpace, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header of %s: %w", path, err)
+	}
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[name] = i
+	}
+	for _, name := range []string{"solarSystemID", "regionID", "x", "y", "z", "security"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("%s has no %s column", path, name)
+		}
+	}
+
+	space := &JumpSpace{index: make(map[int]int)}
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		id, err := strconv.Atoi(rec[col["solarSystemID"]])
+		if err != nil || IsJSpace(id) {
+			continue
+		}
+		sys := jumpSystem{id: id}
+		sys.region, _ = strconv.Atoi(rec[col["regionID"]])
+		sys.security, _ = strconv.ParseFloat(rec[col["security"]], 64)
+		coords := [3]*float64{&sys.x, &sys.y, &sys.z}
+		for i, name := range []string{"x", "y", "z"} {
+			v, err := strconv.ParseFloat(rec[col[name]], 64)
+			if err != nil {
+				return nil, fmt.Errorf("bad %s coordinate for system %d: %w", name, id, err)
+			}
+			*coords[i] = v / metersPerLightYear
+		}
+		space.index[id] = len(space.systems)
+		space.systems = append(space.systems, sys)
+	}
+	return space, nil
+}
+
+// Len returns the number of systems loaded.
+func (js *JumpSpace) Len() int {
+	return len(js.systems)
+}
+
+// Distance returns the distance in light-years between two systems.
+func (js *JumpSpace) Distance(from, to int) (float64, bool) {
+	a, ok1 := js.index[from]
+	b, ok2 := js.index[to]
+	if !ok1 || !ok2 {
+		return 0, false
+	}
+	return js.systems[a].distance(&js.systems[b]), true
+}
+
+func (s *jumpSystem) distance(o *jumpSystem) float64 {
+	dx, dy, dz := s.x-o.x, s.y-o.y, s.z-o.z
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+// jumpable reports whether a jump drive can be used in the system, either
+// to leave it or to land in it. The game rounds security to one decimal
+// place, so anything that shows as 0.5 or above is high-sec. Pochven and
+// Zarzakh block jump drives too.
+func (s *jumpSystem) jumpable() bool {
+	if math.Round(s.security*10)/10 >= 0.5 {
+		return false
+	}
+	return s.region != pochvenRegionID && s.id != ZarzakhSystemID
+}
+
+// JumpableError reports a start or destination a jump drive can't be used in.
+type JumpableError struct {
+	SystemID int
+}
+
+func (e *JumpableError) Error() string {
+	return fmt.Sprintf("jump drives can't be used in system %d", e.SystemID)
+}
+
+// PlanJumps finds a chain of capital jumps from src to dst, each no longer
+// than rangeLY, landing only where jump drives work. It returns the systems
+// in travel order, src and dst included, or nil if dst is out of reach.
+// Avoided systems are never used as midpoints.
+func (js *JumpSpace) PlanJumps(src, dst int, rangeLY float64, mode JumpMode, avoid func(systemID int) bool) ([]int, error) {
+	if rangeLY <= 0 {
+		return nil, fmt.Errorf("jump range must be positive, got %g ly", rangeLY)
+	}
+	for _, id := range []int{src, dst} {
+		i, ok := js.index[id]
+		if !ok || !js.systems[i].jumpable() {
+			return nil, &JumpableError{SystemID: id}
+		}
+	}
+	if src == dst {
+		return []int{src}, nil
+	}
+	srcIdx, dstIdx := int32(js.index[src]), int32(js.index[dst])
+
+	// Bucket the usable systems into cubes one jump range across, so each
+	// system only has to be measured against the 27 cubes around it.
+	type cell [3]int
+	cellOf := func(s *jumpSystem) cell {
+		return cell{int(math.Floor(s.x / rangeLY)), int(math.Floor(s.y / rangeLY)), int(math.Floor(s.z / rangeLY))}
+	}
+	grid := make(map[cell][]int32)
+	for i := range js.systems {
+		s := &js.systems[i]
+		if !s.jumpable() || (avoid != nil && int32(i) != dstIdx && avoid(s.id)) {
+			continue
+		}
+		c := cellOf(s)
+		grid[c] = append(grid[c], int32(i))
+	}
+
+	// Costs are kept in thousandths of a light-year. Fewest jumps adds a
+	// penalty per jump larger than any route's total distance (over 4000 ly),
+	// so the jump count is minimised first and distance only breaks ties.
+	const perJumpPenalty = 1 << 22
+	cost := func(ly float64) int {
+		c := int(ly * 1000)
+		if mode == JumpFewest {
+			c += perJumpPenalty
+		}
+		return c
+	}
+
+	b := getBuffers(len(js.systems))
+	defer bufferPool.Put(b)
+	b.dist[srcIdx] = 0
+	b.pq.push(item{node: srcIdx, dist: 0})
+	for len(b.pq) > 0 {
+		u := b.pq.pop().node
+		if b.visited[u] {
+			continue
+		}
+		b.visited[u] = true
+		if u == dstIdx {
+			break
+		}
+		from := &js.systems[u]
+		c := cellOf(from)
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				for dz := -1; dz <= 1; dz++ {
+					for _, v := range grid[cell{c[0] + dx, c[1] + dy, c[2] + dz}] {
+						if b.visited[v] {
+							continue
+						}
+						d := from.distance(&js.systems[v])
+						if d > rangeLY {
+							continue
+						}
+						if nd := b.dist[u] + cost(d); nd < b.dist[v] {
+							b.dist[v] = nd
+							b.prev[v] = u
+							b.pq.push(item{node: v, dist: nd})
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if b.dist[dstIdx] == unreached {
+		return nil, nil
+	}
+	var path []int
+	for x := dstIdx; x != -1; x = b.prev[x] {
+		path = append(path, js.systems[x].id)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, nil
+}
@@ -0,0 +1,98 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// loadTestJumpSpace writes a small SDE-style system list, positions given in
+// light-years, and loads it.
+func loadTestJumpSpace(t *testing.T) *JumpSpace {
+	t.Helper()
+	systems := []struct {
+		id, region int
+		x, y       float64
+		security   float64
+	}{
+		{1, 1, 0, 0, -0.2},
+		{2, 1, 8, 0, 0.3},
+		{3, 1, 4, 3, 0.0},   // one jump from both 1 and 2 at 5 ly
+		{4, 1, 2.5, 0, 0.1}, // 4 and 5 make a straighter, three-jump path
+		{5, 1, 5.5, 0, 0.1},
+		{6, 1, 4, 0.5, 0.8}, // high-sec
+		{7, pochvenRegionID, 4, -0.5, -1.0},
+		{ZarzakhSystemID, 1, 5, -1, -1.0},
+		{8, 1, 100, 0, -0.5},    // out of reach
+		{9, 1, 7, 0.5, 0.45},    // shows in game as 0.5, so high-sec
+		{31000001, 1, 1, 1, -1}, // J-space is never loaded
+	}
+	var b strings.Builder
+	b.WriteString("regionID,constellationID,solarSystemID,solarSystemName,x,y,z,security\n")
+	for _, s := range systems {
+		fmt.Fprintf(&b, "%d,1,%d,S%d,%.0f,%.0f,0,%g\n", s.region, s.id, s.id, s.x*metersPerLightYear, s.y*metersPerLightYear, s.security)
+	}
+	path := filepath.Join(t.TempDir(), "mapSolarSystems.csv")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	js, err := LoadJumpSpace(path)
+	if err != nil {
+		t.Fatalf("LoadJumpSpace: %v", err)
+	}
+	return js
+}
+
+func TestPlanJumps(t *testing.T) {
+	js := loadTestJumpSpace(t)
+	if js.Len() != 10 {
+		t.Errorf("loaded %d systems, want 10 with J-space left out", js.Len())
+	}
+	if d, ok := js.Distance(1, 2); !ok || d < 7.999 || d > 8.001 {
+		t.Errorf("Distance(1, 2) = %g, %t; want 8 ly", d, ok)
+	}
+
+	avoid3 := func(id int) bool { return id == 3 }
+	tests := []struct {
+		name     string
+		src, dst int
+		rangeLY  float64
+		mode     JumpMode
+		avoid    func(int) bool
+		want     []int
+		badEnd   int // system a JumpableError should name, if any
+		wantErr  bool
+	}{
+		{"fewest jumps", 1, 2, 5, JumpFewest, nil, []int{1, 3, 2}, 0, false},
+		{"least fatigue", 1, 2, 5, JumpLeastFatigue, nil, []int{1, 4, 5, 2}, 0, false},
+		{"avoided midpoint", 1, 2, 5, JumpFewest, avoid3, []int{1, 4, 5, 2}, 0, false},
+		{"in range", 1, 2, 10, JumpFewest, nil, []int{1, 2}, 0, false},
+		{"same system", 1, 1, 5, JumpFewest, nil, []int{1}, 0, false},
+		{"out of reach", 1, 8, 5, JumpFewest, nil, nil, 0, false},
+		{"high-sec destination", 1, 6, 5, JumpFewest, nil, nil, 6, true},
+		{"rounds up to high-sec", 1, 9, 5, JumpFewest, nil, nil, 9, true},
+		{"Pochven start", 7, 2, 5, JumpFewest, nil, nil, 7, true},
+		{"Zarzakh destination", 1, ZarzakhSystemID, 5, JumpFewest, nil, nil, ZarzakhSystemID, true},
+		{"unknown system", 1, 12345, 5, JumpFewest, nil, nil, 12345, true},
+		{"no range", 1, 2, 0, JumpFewest, nil, nil, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := js.PlanJumps(tt.src, tt.dst, tt.rangeLY, tt.mode, tt.avoid)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %t", err, tt.wantErr)
+			}
+			var jumpable *JumpableError
+			if tt.badEnd != 0 && (!errors.As(err, &jumpable) || jumpable.SystemID != tt.badEnd) {
+				t.Errorf("error = %v, want a JumpableError for %d", err, tt.badEnd)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
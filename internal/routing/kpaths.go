@@ -0,0 +1,72 @@
+// internal/routing/kpaths.go
+package routing
+
+import (
+	"slices"
+	"sort"
+)
+
+// KShortestPaths returns up to k loopless routes from src to dst, cheapest
+// first, using Yen's algorithm. Each route is a list of systems in travel order.
+func (s *Snapshot) KShortestPaths(src, dst, k int, opts RouteOptions) [][]int {
+	first := s.Path(src, dst, opts)
+	if first == nil || k < 1 {
+		return nil
+	}
+
+	type candidate struct {
+		path []int
+		cost int
+	}
+	found := [][]int{first}
+	var pending []candidate
+
+	for len(found) < k {
+		last := found[len(found)-1]
+		for i := 0; i < len(last)-1; i++ {
+			spur, root := last[i], last[:i+1]
+
+			ban := &bans{nodes: make(map[int]bool), edges: make(map[[2]int]bool)}
+			for _, p := range found {
+				if len(p) > i && slices.Equal(p[:i+1], root) {
+					ban.edges[[2]int{p[i], p[i+1]}] = true
+				}
+			}
+			for _, id := range root[:i] {
+				ban.nodes[id] = true
+			}
+
+			spurResult := s.search(spur, dst, opts, ban)
+			spurPath := spurResult.PathTo(dst)
+			spurResult.Release()
+			if spurPath == nil {
+				continue
+			}
+			total := append(slices.Clone(root[:i]), spurPath...)
+
+			duplicate := slices.ContainsFunc(found, func(p []int) bool { return slices.Equal(p, total) }) ||
+				slices.ContainsFunc(pending, func(c candidate) bool { return slices.Equal(c.path, total) })
+			if !duplicate {
+				pending = append(pending, candidate{path: total, cost: s.PathCost(total, opts)})
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+		sort.SliceStable(pending, func(a, b int) bool { return pending[a].cost < pending[b].cost })
+		found = append(found, pending[0].path)
+		pending = pending[1:]
+	}
+	return found
+}
+
+// PathCost sums the cost of a route under opts, taking the cheapest
+// connection wherever two systems are linked more than once.
+func (s *Snapshot) PathCost(path []int, opts RouteOptions) int {
+	total := 0
+	for i := 0; i+1 < len(path); i++ {
+		e, _ := s.Jump(path[i], path[i+1], opts)
+		total += opts.cost(e)
+	}
+	return total
+}
@@ -0,0 +1,90 @@
+package routing
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// loadTinyGraph builds a graph from a list of two-way stargates, all in one
+// constellation and region.
+func loadTinyGraph(t *testing.T, gates [][2]int) *Graph {
+	t.Helper()
+	var b strings.Builder
+	b.WriteString("fromRegionID,fromConstellationID,fromSolarSystemID,toSolarSystemID,toConstellationID,toRegionID\n")
+	for _, g := range gates {
+		fmt.Fprintf(&b, "1,1,%d,%d,1,1\n1,1,%d,%d,1,1\n", g[0], g[1], g[1], g[0])
+	}
+	path := filepath.Join(t.TempDir(), "jumps.csv")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	g := NewGraph()
+	if err := g.LoadCSV(path); err != nil {
+		t.Fatalf("LoadCSV: %v", err)
+	}
+	return g
+}
+
+func TestKShortestPaths(t *testing.T) {
+	// Three ways from 1 to 4: via 2, via 3, and the long way via 5 and 6.
+	tiny := loadTinyGraph(t, [][2]int{{1, 2}, {2, 4}, {1, 3}, {3, 4}, {1, 5}, {5, 6}, {6, 4}}).Snapshot()
+	eve := loadTestGraph(t).Snapshot()
+	opts := RouteOptions{Profile: ProfileShortest}
+
+	tests := []struct {
+		name     string
+		snap     *Snapshot
+		src, dst int
+		k        int
+		want     int   // routes expected
+		costs    []int // expected costs, if known
+	}{
+		{"all of them", tiny, 1, 4, 5, 3, []int{2, 2, 3}},
+		{"fewer than exist", tiny, 1, 4, 2, 2, []int{2, 2}},
+		{"one", tiny, 1, 4, 1, 1, []int{2}},
+		{"k of zero", tiny, 1, 4, 0, 0, nil},
+		{"unreachable", tiny, 1, 99, 3, 0, nil},
+		{"Jita to Amarr", eve, jitaID, amarrID, 5, 5, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes := tt.snap.KShortestPaths(tt.src, tt.dst, tt.k, opts)
+			if len(routes) != tt.want {
+				t.Fatalf("got %d routes, want %d: %v", len(routes), tt.want, routes)
+			}
+			if len(routes) > 0 && !slices.Equal(routes[0], tt.snap.Path(tt.src, tt.dst, opts)) {
+				t.Errorf("first route %v isn't the shortest path", routes[0])
+			}
+			var costs []int
+			for i, r := range routes {
+				if r[0] != tt.src || r[len(r)-1] != tt.dst {
+					t.Errorf("route %d runs %d→%d", i, r[0], r[len(r)-1])
+				}
+				if len(slices.Compact(slices.Sorted(slices.Values(r)))) != len(r) {
+					t.Errorf("route %d visits a system twice: %v", i, r)
+				}
+				for j := 0; j+1 < len(r); j++ {
+					if _, ok := tt.snap.Jump(r[j], r[j+1], opts); !ok {
+						t.Errorf("route %d jumps %d→%d, which aren't connected", i, r[j], r[j+1])
+					}
+				}
+				for _, earlier := range routes[:i] {
+					if slices.Equal(earlier, r) {
+						t.Errorf("route %d repeats an earlier one: %v", i, r)
+					}
+				}
+				costs = append(costs, tt.snap.PathCost(r, opts))
+			}
+			if !slices.IsSorted(costs) {
+				t.Errorf("routes aren't cheapest first: costs %v", costs)
+			}
+			if tt.costs != nil && !slices.Equal(costs, tt.costs) {
+				t.Errorf("costs %v, want %v", costs, tt.costs)
+			}
+		})
+	}
+}
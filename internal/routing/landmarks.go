@@ -0,0 +1,157 @@
+// internal/routing/landmarks.go
+package routing
+
+// numLandmarks is how many landmarks the A* heuristic measures from. More
+// landmarks give tighter estimates at the cost of memory and per-node work.
+const numLandmarks = 16
+
+// pickLandmarks chooses landmarks spread across the static graph, each as
+// far as possible from the ones already picked, and records every system's
+// jump count from each of them. Systems a landmark can't reach by stargate
+// are recorded as -1, which also makes disconnected regions such as Pochven
+// pick up a landmark of their own.
+func (g *Graph) pickLandmarks() {
+	g.landmarks = nil
+	if len(g.ids) == 0 {
+		return
+	}
+
+	// nearest[v] is the jump count from v to its closest landmark so far.
+	nearest := make([]int32, len(g.ids))
+	for i := range nearest {
+		nearest[i] = -1
+	}
+	next := int32(0)
+	queue := make([]int32, 0, len(g.ids))
+	for len(g.landmarks) < numLandmarks {
+		dist := g.hopsFrom(next, queue)
+		g.landmarks = append(g.landmarks, dist)
+
+		far, farthest := int32(-1), int32(0)
+		for v, d := range dist {
+			if d >= 0 && (nearest[v] < 0 || d < nearest[v]) {
+				nearest[v] = d
+			}
+			if nearest[v] < 0 {
+				// Unreachable from every landmark so far: pick it next.
+				far, farthest = int32(v), 1<<30
+				continue
+			}
+			if nearest[v] > farthest {
+				far, farthest = int32(v), nearest[v]
+			}
+		}
+		if far < 0 || farthest == 0 {
+			break // every system is already a landmark
+		}
+		next = far
+	}
+}
+
+// hopsFrom runs a breadth-first search over the stargates from src and
+// returns the jump count to every static system, -1 where unreachable.
+func (g *Graph) hopsFrom(src int32, queue []int32) []int32 {
+	dist := make([]int32, len(g.ids))
+	for i := range dist {
+		dist[i] = -1
+	}
+	dist[src] = 0
+	queue = append(queue[:0], src)
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, e := range g.staticEdges(u) {
+			if dist[e.to] < 0 {
+				dist[e.to] = dist[u] + 1
+				queue = append(queue, e.to)
+			}
+		}
+	}
+	return dist
+}
+
+// landmarkBound is a lower bound on the stargate jumps between two static
+// systems, from the triangle inequality: d(v,t) >= |d(L,t) - d(L,v)|.
+func (g *Graph) landmarkBound(v, t int32) int {
+	best := int32(0)
+	for _, dist := range g.landmarks {
+		dv, dt := dist[v], dist[t]
+		if dv < 0 || dt < 0 {
+			continue
+		}
+		d := dt - dv
+		if d < 0 {
+			d = -d
+		}
+		if d > best {
+			best = d
+		}
+	}
+	return int(best)
+}
+
+// heuristic estimates the remaining cost of a route to one target. It never
+// overestimates, so A* with it still finds the cheapest route.
+//
+// Landmark bounds only hold over stargates, and a wormhole can cut across the
+// map. Any route that uses the overlay ends with a stretch of stargates from
+// wherever its last overlay link lands, so it costs at least the cheapest
+// link plus the landmark bound from the nearest overlay endpoint. Each system
+// is estimated at the smaller of that and its own landmark bound.
+type heuristic struct {
+	graph      *Graph
+	target     int32 // static index of the target, -1 if it isn't a static system
+	viaOverlay int   // lower bound on any route that uses an overlay link
+}
+
+// heuristicTo prepares the heuristic for routes ending at dst. Overlay links
+// opts rules out are left out of the bound, since the search can't take them.
+func (s *Snapshot) heuristicTo(dst int32, opts RouteOptions) heuristic {
+	h := heuristic{graph: s.graph, target: -1, viaOverlay: unreached}
+	if int(dst) >= len(s.graph.ids) || len(s.graph.landmarks) == 0 {
+		return h
+	}
+	h.target = dst
+
+	cheapest := unreached
+	for i := range s.overlay.links {
+		l := &s.overlay.links[i]
+		if !opts.allowsLink(l) {
+			continue
+		}
+		if l.Cost < cheapest {
+			cheapest = l.Cost
+		}
+		for _, id := range [2]int{l.From, l.To} {
+			// Endpoints outside the stargate graph can't be where the last
+			// overlay link lands, since no stargate leads on from them.
+			idx, ok := s.graph.index[id]
+			if !ok {
+				continue
+			}
+			if b := s.graph.landmarkBound(idx, dst); b < h.viaOverlay {
+				h.viaOverlay = b
+			}
+		}
+	}
+	if cheapest < 0 {
+		cheapest = 0
+	}
+	if h.viaOverlay != unreached {
+		h.viaOverlay += cheapest
+	}
+	return h
+}
+
+// estimate returns the lower bound on the cost from the system at dense index v.
+func (h *heuristic) estimate(v int32) int {
+	if h.target < 0 {
+		return 0
+	}
+	if int(v) >= len(h.graph.ids) {
+		// Off the stargate graph; the only way on is through the overlay.
+		return h.viaOverlay
+	}
+	// Every stargate jump costs at least one under every profile.
+	return min(h.graph.landmarkBound(v, h.target), h.viaOverlay)
+}
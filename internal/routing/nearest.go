@@ -0,0 +1,108 @@
+// internal/routing/nearest.go
+package routing
+
+// Preset names a set of destinations to route to the nearest of.
+type Preset string
+
+const (
+	PresetHighSec  Preset = "high-sec"
+	PresetTradeHub Preset = "trade-hub"
+	PresetThera    Preset = "thera"
+	PresetTurnur   Preset = "turnur"
+)
+
+// PresetOption is a preset paired with the label shown on the route form.
+type PresetOption struct {
+	Value Preset
+	Label string
+}
+
+// Presets lists the nearest-of-set destinations in the order the form shows them.
+var Presets = []PresetOption{
+	{PresetHighSec, "Nearest high-sec system"},
+	{PresetTradeHub, "Nearest trade hub"},
+	{PresetThera, "Nearest Thera connection"},
+	{PresetTurnur, "Nearest Turnur connection"},
+}
+
+// ParsePreset maps a form value to a Preset. The bool is false when the
+// value names no preset, meaning the route has a fixed destination.
+func ParsePreset(s string) (Preset, bool) {
+	for _, p := range Presets {
+		if string(p.Value) == s {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+// Label returns the text the form shows for the preset.
+func (p Preset) Label() string {
+	for _, o := range Presets {
+		if o.Value == p {
+			return o.Label
+		}
+	}
+	return string(p)
+}
+
+// PresetTargets returns the test for whether a system belongs to a preset's
+// destinations. High-sec needs opts.Security, and the Thera and Turnur
+// presets only count connections opts allows.
+func (s *Snapshot) PresetTargets(p Preset, opts RouteOptions) func(systemID int) bool {
+	switch p {
+	case PresetHighSec:
+		return func(id int) bool {
+			if opts.Security == nil || IsJSpace(id) {
+				return false
+			}
+			sec, ok := opts.Security(id)
+			return ok && SecurityClass(sec) == "high-sec"
+		}
+	case PresetTradeHub:
+		return func(id int) bool {
+			_, ok := TradeHubs[id]
+			return ok
+		}
+	case PresetThera, PresetTurnur:
+		kind, hub := KindThera, TheraSystemID
+		if p == PresetTurnur {
+			kind, hub = KindTurnur, TurnurSystemID
+		}
+		// The far ends of the hub's connections, not the hub itself.
+		entries := make(map[int]bool)
+		for i := range s.overlay.links {
+			l := &s.overlay.links[i]
+			if l.Kind != kind || !opts.allowsLink(l) {
+				continue
+			}
+			for _, id := range [2]int{l.From, l.To} {
+				if id != hub {
+					entries[id] = true
+				}
+			}
+		}
+		return func(id int) bool { return entries[id] }
+	}
+	return func(int) bool { return false }
+}
+
+// Nearest runs a single search from src and stops at the cheapest system
+// isTarget accepts, which may be src itself. It returns that system and the
+// route to it, or a nil route if no target can be reached. Avoided systems
+// other than src are never picked as the destination.
+func (s *Snapshot) Nearest(src int, isTarget func(systemID int) bool, opts RouteOptions) (int, []int) {
+	srcIdx, ok := s.indexOf(src)
+	if !ok {
+		return 0, nil
+	}
+	result, found := s.dijkstraUntil(srcIdx, -1, opts, nil, func(u int32) bool {
+		return isTarget(s.idOf(u))
+	})
+	defer result.Release()
+	if found < 0 {
+		return 0, nil
+	}
+	dst := s.idOf(found)
+	return dst, result.PathTo(dst)
+}
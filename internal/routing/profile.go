@@ -0,0 +1,163 @@
+// internal/routing/profile.go
+package routing
+
+import (
+	"math"
+	"time"
+)
+
+// Profile selects how each jump is costed during the search.
+type Profile string
+
+const (
+	ProfileShortest   Profile = "shortest"
+	ProfileSafer      Profile = "safer"
+	ProfileLessSecure Profile = "less-secure"
+	ProfileAvoidNull  Profile = "avoid-null"
+	ProfileMinRisk    Profile = "min-risk"
+)
+
+// ProfileOption is a profile paired with the label shown on the route form.
+type ProfileOption struct {
+	Value Profile
+	Label string
+}
+
+// Profiles lists the available route profiles in the order the form shows them.
+var Profiles = []ProfileOption{
+	{ProfileShortest, "Shortest"},
+	{ProfileSafer, "Safer (prefer high-sec)"},
+	{ProfileLessSecure, "Less secure (prefer low/null)"},
+	{ProfileAvoidNull, "Avoid null-sec"},
+	{ProfileMinRisk, "Least risk (avoid recent kills)"},
+}
+
+// ParseProfile maps a form value to a Profile, falling back to the shortest route.
+func ParseProfile(s string) Profile {
+	for _, p := range Profiles {
+		if string(p.Value) == s {
+			return p.Value
+		}
+	}
+	return ProfileShortest
+}
+
+// Algorithm selects how the route is searched for. Every algorithm finds a
+// route of the same cost; they differ in how much of the map they explore
+// on the way.
+type Algorithm string
+
+const (
+	AlgorithmDijkstra      Algorithm = "dijkstra"
+	AlgorithmBidirectional Algorithm = "bidirectional" // Dijkstra from both ends at once
+	AlgorithmAStar         Algorithm = "astar"         // A* with the landmark heuristic
+)
+
+// ParseAlgorithm maps a form value to an Algorithm, falling back to Dijkstra.
+func ParseAlgorithm(s string) Algorithm {
+	switch a := Algorithm(s); a {
+	case AlgorithmBidirectional, AlgorithmAStar:
+		return a
+	}
+	return AlgorithmDijkstra
+}
+
+// SecurityFunc reports the security status of a solar system.
+// The bool is false when the system is unknown.
+type SecurityFunc func(systemID int) (float64, bool)
+
+// RouteOptions controls how a route is searched for.
+type RouteOptions struct {
+	Profile  Profile
+	Security SecurityFunc
+	Risk     RiskFunc   // used by ProfileMinRisk
+	Avoid    *Avoidance // start and destination are always allowed
+	Ship     ShipClass  // drops wormholes the ship can't fit through
+	Bridges  bool       // use the jump bridge network
+
+	// Wormholes drops holes the pilot doesn't want to risk, judged as of Now.
+	Wormholes WormholeFilter
+	Now       time.Time
+
+	// Algorithm only applies to searches with a destination; searches of
+	// the whole graph always use Dijkstra.
+	Algorithm Algorithm
+}
+
+// allows reports whether the search may use edge e at all.
+func (o RouteOptions) allows(e Edge) bool {
+	if e.Link == nil {
+		return true
+	}
+	return o.allowsLink(e.Link)
+}
+
+// allowsLink reports whether the search may use an overlay link. Jump
+// bridges are opt-in and closed to capitals; wormholes have to pass the
+// ship and wormhole filters.
+func (o RouteOptions) allowsLink(l *WHLink) bool {
+	if l.Kind == KindBridge {
+		return o.Bridges && o.Ship != ShipCapital
+	}
+	return o.Ship.fits(l) && o.Wormholes.Allows(l, o.Now)
+}
+
+// Per-class jump penalties. A penalised jump costs as much as this many
+// ordinary jumps, so the search will take a long detour before using one.
+const (
+	cheapJump  = 1
+	avoidJump  = 50
+	unsafeJump = 100
+)
+
+// riskJump is the cost of a quiet jump under ProfileMinRisk. Each point of
+// risk in the system landed in adds the same again, so a hotspot is worth a
+// detour of several jumps.
+const riskJump = 10
+
+// SecurityClass buckets a security status the same way the route results show it.
+func SecurityClass(sec float64) string {
+	if sec >= 0.5 {
+		return "high-sec"
+	} else if sec > 0.0 {
+		return "low-sec"
+	}
+	return "null-sec"
+}
+
+// cost returns the cost of taking edge e under the selected profile. Profiles
+// are costed on the security, or the risk, of the system the jump lands in.
+func (o RouteOptions) cost(e Edge) int {
+	if o.Profile == ProfileMinRisk {
+		if o.Risk == nil {
+			return e.Weight
+		}
+		return e.Weight * (riskJump + int(math.Round(o.Risk(e.To)*riskJump)))
+	}
+	if o.Profile == ProfileShortest || o.Profile == "" || o.Security == nil {
+		return e.Weight
+	}
+	sec, ok := o.Security(e.To)
+	if !ok {
+		return e.Weight
+	}
+
+	multiplier := cheapJump
+	switch class := SecurityClass(sec); o.Profile {
+	case ProfileSafer:
+		if class == "low-sec" {
+			multiplier = avoidJump
+		} else if class == "null-sec" {
+			multiplier = unsafeJump
+		}
+	case ProfileLessSecure:
+		if class == "high-sec" {
+			multiplier = avoidJump
+		}
+	case ProfileAvoidNull:
+		if class == "null-sec" {
+			multiplier = unsafeJump
+		}
+	}
+	return e.Weight * multiplier
+}
@@ -0,0 +1,69 @@
+// internal/routing/reach.go
+package routing
+
+// TradeHubs are the main market systems, by system ID.
+var TradeHubs = map[int]string{
+	30000142: "Jita",
+	30002187: "Amarr",
+	30002659: "Dodixie",
+	30002510: "Rens",
+	30002053: "Hek",
+}
+
+// Reach is one system found by Reachable.
+type Reach struct {
+	SystemID int
+	Jumps    int
+	Entry    EdgeKind // KindThera or KindTurnur if the system has a usable connection to either
+}
+
+// Reachable returns every system within maxJumps jumps of src, src included,
+// nearest first. Every connection counts as one jump whatever the profile,
+// but opts still decides which wormholes may be taken and which systems are
+// avoided.
+func (s *Snapshot) Reachable(src, maxJumps int, opts RouteOptions) []Reach {
+	srcIdx, ok := s.indexOf(src)
+	if !ok {
+		return nil
+	}
+	b := getBuffers(s.size())
+	defer bufferPool.Put(b)
+
+	found := []Reach{{SystemID: src}}
+	b.dist[srcIdx] = 0
+	queue := []int32{srcIdx}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		if b.dist[u] == maxJumps || s.noTransit(u, srcIdx) {
+			continue
+		}
+		for _, edges := range s.neighbors(u) {
+			for _, e := range edges {
+				if b.dist[e.to] != unreached || !s.usable(u, e, -1, opts, nil) {
+					continue
+				}
+				b.dist[e.to] = b.dist[u] + 1
+				found = append(found, Reach{SystemID: e.To, Jumps: b.dist[e.to]})
+				queue = append(queue, e.to)
+			}
+		}
+	}
+
+	at := make(map[int]int, len(found))
+	for i, r := range found {
+		at[r.SystemID] = i
+	}
+	for i := range s.overlay.links {
+		l := &s.overlay.links[i]
+		if (l.Kind != KindThera && l.Kind != KindTurnur) || !opts.allowsLink(l) {
+			continue
+		}
+		for _, id := range [2]int{l.From, l.To} {
+			if j, ok := at[id]; ok {
+				found[j].Entry = l.Kind
+			}
+		}
+	}
+	return found
+}
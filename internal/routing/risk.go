@@ -0,0 +1,25 @@
+// internal/routing/risk.go
+package routing
+
+// RiskFunc returns a system's current risk score, 0 when nothing is happening there.
+type RiskFunc func(systemID int) float64
+
+// HotspotRisk is the risk score from which a system is flagged as a hotspot on a route.
+const HotspotRisk = 5
+
+// RiskScore rates how dangerous a system is right now from its ship and pod
+// kills in the last hour and its long-run hourly average of the same.
+//
+// The recent kills are scaled by how far above normal they are, so three
+// kills in a system that rarely sees any rate higher than three in one that
+// always does, while a busy system still scores on its raw kill count.
+func RiskScore(recentKills int, baseline float64) float64 {
+	if recentKills <= 0 {
+		return 0
+	}
+	if baseline < 0 {
+		baseline = 0
+	}
+	r := float64(recentKills)
+	return r * (r + 1) / (baseline + 1)
+}
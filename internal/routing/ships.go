@@ -0,0 +1,203 @@
+// internal/routing/ships.go
+package routing
+
+import "strings"
+
+// ShipSize is the largest hull a wormhole lets through, smallest first.
+type ShipSize int
+
+const (
+	SizeUnknown ShipSize = iota
+	SizeSmall            // frigates and destroyers
+	SizeMedium           // cruisers and battlecruisers
+	SizeLarge            // battleships and industrials
+	SizeXLarge           // freighters
+	SizeCapital          // capitals
+)
+
+// unknownHoleSize is assumed for holes whose type isn't known, such as the
+// K162 side of a connection. Anything up to a battleship is let through;
+// freighters and capitals need the hole's type to be known.
+const unknownHoleSize = SizeLarge
+
+// ShipClass is the hull a pilot is flying, used to drop holes it can't jump.
+type ShipClass string
+
+const (
+	ShipAny        ShipClass = ""
+	ShipFrigate    ShipClass = "frigate"
+	ShipCruiser    ShipClass = "cruiser"
+	ShipBattleship ShipClass = "battleship"
+	ShipFreighter  ShipClass = "freighter"
+	ShipCapital    ShipClass = "capital"
+)
+
+// ShipClassOption is a ship class paired with the label shown on the route form.
+type ShipClassOption struct {
+	Value ShipClass
+	Label string
+}
+
+// ShipClasses lists the ship classes in the order the form shows them.
+var ShipClasses = []ShipClassOption{
+	{ShipAny, "Any ship"},
+	{ShipFrigate, "Frigate / Destroyer"},
+	{ShipCruiser, "Cruiser / Battlecruiser"},
+	{ShipBattleship, "Battleship / Industrial"},
+	{ShipFreighter, "Freighter"},
+	{ShipCapital, "Capital"},
+}
+
+// ParseShipClass maps a form value to a ShipClass, falling back to any ship.
+func ParseShipClass(s string) ShipClass {
+	for _, c := range ShipClasses {
+		if string(c.Value) == s {
+			return c.Value
+		}
+	}
+	return ShipAny
+}
+
+// shipGroups maps the SDE inventory group of each ship hull to the class
+// the route form uses for it. Groups missing here, such as new hulls, fall
+// back to any ship.
+var shipGroups = map[int]ShipClass{
+	// Frigates and destroyers.
+	25: ShipFrigate, 29: ShipFrigate, 31: ShipFrigate, 237: ShipFrigate,
+	324: ShipFrigate, 420: ShipFrigate, 541: ShipFrigate, 830: ShipFrigate,
+	831: ShipFrigate, 834: ShipFrigate, 893: ShipFrigate, 1022: ShipFrigate,
+	1283: ShipFrigate, 1305: ShipFrigate, 1527: ShipFrigate, 1534: ShipFrigate,
+	// Cruisers, battlecruisers and mining barges.
+	26: ShipCruiser, 358: ShipCruiser, 419: ShipCruiser, 463: ShipCruiser,
+	540: ShipCruiser, 543: ShipCruiser, 832: ShipCruiser, 833: ShipCruiser,
+	894: ShipCruiser, 906: ShipCruiser, 963: ShipCruiser, 1201: ShipCruiser,
+	1972: ShipCruiser,
+	// Battleships and industrials.
+	27: ShipBattleship, 28: ShipBattleship, 380: ShipBattleship, 898: ShipBattleship,
+	900: ShipBattleship, 941: ShipBattleship, 1202: ShipBattleship,
+	// Freighters.
+	513: ShipFreighter, 902: ShipFreighter,
+	// Capitals.
+	30: ShipCapital, 485: ShipCapital, 547: ShipCapital, 659: ShipCapital,
+	883: ShipCapital, 1538: ShipCapital, 4594: ShipCapital,
+}
+
+// ShipClassForGroup returns the ship class of a hull from its inventory group ID.
+func ShipClassForGroup(groupID int) ShipClass {
+	return shipGroups[groupID]
+}
+
+// Size returns the smallest hole size the ship class fits through.
+func (c ShipClass) Size() ShipSize {
+	switch c {
+	case ShipFrigate:
+		return SizeSmall
+	case ShipCruiser:
+		return SizeMedium
+	case ShipBattleship:
+		return SizeLarge
+	case ShipFreighter:
+		return SizeXLarge
+	case ShipCapital:
+		return SizeCapital
+	}
+	return SizeUnknown
+}
+
+// Max jump mass (kg) for each hole size.
+const (
+	smallJumpMass  = 5_000_000
+	mediumJumpMass = 62_000_000
+	largeJumpMass  = 375_000_000
+	xlargeJumpMass = 1_000_000_000
+)
+
+// wormholeJumpMass is the max jump mass (kg) of each wormhole type code.
+// Types missing from the table, K162 included, are treated as unknownHoleSize.
+var wormholeJumpMass = map[string]int64{
+	// Frigate holes
+	"E004": 5_000_000, "L005": 5_000_000, "Z006": 5_000_000, "M001": 5_000_000,
+	"C008": 5_000_000, "G008": 5_000_000, "Q003": 5_000_000, "A009": 5_000_000,
+
+	// Holes into and out of class 1 space
+	"H121": 62_000_000, "C125": 62_000_000, "O883": 62_000_000, "M609": 62_000_000,
+	"L614": 62_000_000, "S804": 62_000_000, "N110": 62_000_000, "J244": 62_000_000,
+	"Z060": 62_000_000, "Z647": 62_000_000, "V301": 62_000_000, "P060": 62_000_000,
+	"Y790": 62_000_000, "Z971": 62_000_000, "Q317": 62_000_000,
+
+	// Battleship holes
+	"D382": 375_000_000, "O477": 375_000_000, "Y683": 375_000_000, "N062": 375_000_000,
+	"R474": 375_000_000, "B274": 375_000_000, "A239": 375_000_000, "E545": 375_000_000,
+	"I182": 375_000_000, "N968": 375_000_000, "T405": 375_000_000, "N770": 375_000_000,
+	"A982": 375_000_000, "D845": 375_000_000, "U210": 375_000_000, "K346": 375_000_000,
+	"N766": 375_000_000, "C247": 375_000_000, "X877": 375_000_000, "H900": 375_000_000,
+	"U574": 375_000_000, "S047": 375_000_000, "N290": 375_000_000, "D364": 375_000_000,
+	"M267": 375_000_000, "E175": 375_000_000, "R943": 375_000_000, "X702": 375_000_000,
+	"O128": 375_000_000, "M555": 375_000_000, "B041": 375_000_000, "F135": 375_000_000,
+	"T458": 375_000_000, "M164": 375_000_000, "L031": 375_000_000,
+
+	// Freighter holes
+	"D792": 1_000_000_000, "B520": 1_000_000_000, "C391": 1_000_000_000, "C248": 1_000_000_000,
+	"A641": 1_000_000_000, "R051": 1_000_000_000, "V283": 1_000_000_000, "N432": 1_000_000_000,
+
+	// Capital holes
+	"H296": 1_350_000_000, "V753": 1_350_000_000, "V911": 1_350_000_000, "W237": 1_350_000_000,
+	"C140": 1_350_000_000, "Z142": 1_350_000_000, "U319": 1_350_000_000, "S199": 1_350_000_000,
+	"K329": 1_800_000_000,
+}
+
+// SizeForType returns the largest hull a wormhole type code lets through.
+func SizeForType(code string) ShipSize {
+	mass, ok := wormholeJumpMass[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return SizeUnknown
+	}
+	switch {
+	case mass <= smallJumpMass:
+		return SizeSmall
+	case mass <= mediumJumpMass:
+		return SizeMedium
+	case mass <= largeJumpMass:
+		return SizeLarge
+	case mass <= xlargeJumpMass:
+		return SizeXLarge
+	}
+	return SizeCapital
+}
+
+// ParseMaxShipSize maps EVE-Scout's max_ship_size values to a ShipSize.
+func ParseMaxShipSize(s string) ShipSize {
+	switch strings.ToLower(s) {
+	case "small":
+		return SizeSmall
+	case "medium":
+		return SizeMedium
+	case "large":
+		return SizeLarge
+	case "xlarge":
+		return SizeXLarge
+	case "capital":
+		return SizeCapital
+	}
+	return SizeUnknown
+}
+
+// fits reports whether the selected ship can take a wormhole link. Once a
+// ship class is picked, holes at critical mass are dropped whatever the
+// hull: a big one can collapse the hole itself, and a small one can be cut
+// off by whoever jumps next. With any ship, the Ignore critical mass filter
+// decides.
+func (c ShipClass) fits(l *WHLink) bool {
+	need := c.Size()
+	if need == SizeUnknown {
+		return true
+	}
+	size := l.MaxShipSize
+	if size == SizeUnknown {
+		size = unknownHoleSize
+	}
+	if size < need {
+		return false
+	}
+	return !l.MassCritical()
+}
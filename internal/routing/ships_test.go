@@ -0,0 +1,53 @@
+package routing
+
+import "testing"
+
+func TestShipClassFits(t *testing.T) {
+	tests := []struct {
+		name string
+		ship ShipClass
+		link WHLink
+		want bool
+	}{
+		{"any ship, any hole", ShipAny, WHLink{MaxShipSize: SizeSmall}, true},
+		{"any ship, critical hole", ShipAny, WHLink{MaxShipSize: SizeLarge, Mass: "critical"}, true},
+		{"frigate through frigate hole", ShipFrigate, WHLink{MaxShipSize: SizeSmall}, true},
+		{"cruiser through frigate hole", ShipCruiser, WHLink{MaxShipSize: SizeSmall}, false},
+		{"cruiser through medium hole", ShipCruiser, WHLink{MaxShipSize: SizeMedium}, true},
+		{"battleship through unknown hole", ShipBattleship, WHLink{}, true},
+		{"freighter through unknown hole", ShipFreighter, WHLink{}, false},
+		{"freighter through freighter hole", ShipFreighter, WHLink{MaxShipSize: SizeXLarge}, true},
+		{"capital through freighter hole", ShipCapital, WHLink{MaxShipSize: SizeXLarge}, false},
+		{"capital through capital hole", ShipCapital, WHLink{MaxShipSize: SizeCapital}, true},
+		{"frigate through critical hole", ShipFrigate, WHLink{MaxShipSize: SizeLarge, Mass: "critical"}, false},
+		{"battleship through critical hole", ShipBattleship, WHLink{MaxShipSize: SizeLarge, Mass: "Critical"}, false},
+		{"battleship through destab hole", ShipBattleship, WHLink{MaxShipSize: SizeLarge, Mass: "destab"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ship.fits(&tt.link); got != tt.want {
+				t.Errorf("fits = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSizeForType(t *testing.T) {
+	tests := []struct {
+		code string
+		want ShipSize
+	}{
+		{"E004", SizeSmall},
+		{"H121", SizeMedium},
+		{"b274", SizeLarge},
+		{" D792 ", SizeXLarge},
+		{"K329", SizeCapital},
+		{"K162", SizeUnknown},
+		{"", SizeUnknown},
+	}
+	for _, tt := range tests {
+		if got := SizeForType(tt.code); got != tt.want {
+			t.Errorf("SizeForType(%q) = %d, want %d", tt.code, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+// internal/routing/snapshot.go
+package routing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// Overlay is an immutable set of wormhole connections layered over the
+// static stargate graph. A new Overlay is built for every change and
+// swapped in whole, so readers never see one half-updated.
+//
+// Systems the stargate graph doesn't know, such as J-space, are given dense
+// indexes after the static ones.
+type Overlay struct {
+	adj        [][]Edge // dense index -> wormhole edges
+	extraIDs   []int    // dense index - len(static) -> system ID
+	extraIndex map[int]int32
+	links      []WHLink
+	version    string
+	createdAt  time.Time
+}
+
+// newOverlay indexes links into an overlay for g. The slice is copied, so
+// the caller may reuse it afterwards.
+func (g *Graph) newOverlay(links []WHLink) *Overlay {
+	o := &Overlay{
+		extraIndex: make(map[int]int32),
+		links:      append([]WHLink(nil), links...),
+		createdAt:  time.Now(),
+	}
+	indexOf := func(id int) int32 {
+		if idx, ok := g.index[id]; ok {
+			return idx
+		}
+		if idx, ok := o.extraIndex[id]; ok {
+			return idx
+		}
+		idx := int32(len(g.ids) + len(o.extraIDs))
+		o.extraIndex[id] = idx
+		o.extraIDs = append(o.extraIDs, id)
+		return idx
+	}
+
+	type half struct {
+		from int32
+		edge Edge
+	}
+	halves := make([]half, 0, 2*len(o.links))
+	for i := range o.links {
+		l := &o.links[i]
+		if l.Kind == "" {
+			l.Kind = KindWormhole
+		}
+		from, to := indexOf(l.From), indexOf(l.To)
+		halves = append(halves,
+			half{from, Edge{To: l.To, Weight: l.Cost, Kind: l.Kind, Link: l, to: to}},
+			half{to, Edge{To: l.From, Weight: l.Cost, Kind: l.Kind, Link: l, to: from}},
+		)
+	}
+	if len(halves) > 0 {
+		o.adj = make([][]Edge, len(g.ids)+len(o.extraIDs))
+		for _, h := range halves {
+			o.adj[h.from] = append(o.adj[h.from], h.edge)
+		}
+	}
+	o.version = contentHash(o.links)
+	return o
+}
+
+// Version is a hash of the overlay's links. Two overlays with the same
+// connections have the same version, whatever order they were fetched in.
+func (o *Overlay) Version() string {
+	return o.version
+}
+
+// CreatedAt is when the overlay was built.
+func (o *Overlay) CreatedAt() time.Time {
+	return o.createdAt
+}
+
+// Links returns the connections in the overlay. Callers must not modify them.
+func (o *Overlay) Links() []WHLink {
+	return o.links
+}
+
+// contentHash hashes links in a stable order so that refetching the same
+// connections yields the same version.
+func contentHash(links []WHLink) string {
+	sorted := append([]WHLink(nil), links...)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.FromSignature < b.FromSignature
+	})
+	data, _ := json.Marshal(sorted)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Snapshot is a consistent view of the static graph plus one wormhole
+// overlay. Every query a request makes should go through the same snapshot.
+type Snapshot struct {
+	graph   *Graph
+	overlay *Overlay
+}
+
+// Snapshot returns a view of the graph with the currently published overlay.
+// It takes no locks and copies nothing.
+func (g *Graph) Snapshot() *Snapshot {
+	return &Snapshot{graph: g, overlay: g.overlay.Load()}
+}
+
+// Overlay returns the overlay the snapshot was taken with.
+func (s *Snapshot) Overlay() *Overlay {
+	return s.overlay
+}
+
+// size is the number of dense indexes in use, static and overlay.
+func (s *Snapshot) size() int {
+	return len(s.graph.ids) + len(s.overlay.extraIDs)
+}
+
+// indexOf returns a system's dense index.
+func (s *Snapshot) indexOf(id int) (int32, bool) {
+	if idx, ok := s.graph.index[id]; ok {
+		return idx, true
+	}
+	idx, ok := s.overlay.extraIndex[id]
+	return idx, ok
+}
+
+// idOf returns the system ID at a dense index.
+func (s *Snapshot) idOf(idx int32) int {
+	if n := int32(len(s.graph.ids)); idx >= n {
+		return s.overlay.extraIDs[idx-n]
+	}
+	return s.graph.ids[idx]
+}
+
+// neighbors returns the stargate and overlay edges out of u as two slices,
+// so that nothing has to be copied to combine them.
+func (s *Snapshot) neighbors(u int32) [2][]Edge {
+	var wormholes []Edge
+	if int(u) < len(s.overlay.adj) {
+		wormholes = s.overlay.adj[u]
+	}
+	return [2][]Edge{s.graph.staticEdges(u), wormholes}
+}
+
+// Avoids reports whether the route must stay out of the given system.
+func (s *Snapshot) Avoids(a *Avoidance, systemID int) bool {
+	return s.graph.Avoids(a, systemID)
+}
+
+// SpaceOf returns the special space a system belongs to.
+func (s *Snapshot) SpaceOf(systemID int) Space {
+	return s.graph.SpaceOf(systemID)
+}
+
+// Jump returns the connection a route takes between two adjacent systems,
+// picking the cheapest one opts allows when they are linked more than once.
+func (s *Snapshot) Jump(from, to int, opts RouteOptions) (Edge, bool) {
+	var best Edge
+	found := false
+	u, ok := s.indexOf(from)
+	if !ok {
+		return best, false
+	}
+	for _, edges := range s.neighbors(u) {
+		for _, e := range edges {
+			if e.To != to || !opts.allows(e) {
+				continue
+			}
+			if !found || opts.cost(e) < opts.cost(best) {
+				best, found = e, true
+			}
+		}
+	}
+	return best, found
+}
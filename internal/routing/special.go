@@ -0,0 +1,51 @@
+// internal/routing/special.go
+package routing
+
+const (
+	pochvenRegionID = 10000070
+	ZarzakhSystemID = 30100000
+)
+
+// Space says whether a system follows any special travel rules.
+type Space string
+
+const (
+	SpaceNormal  Space = ""
+	SpacePochven Space = "pochven"
+	SpaceZarzakh Space = "zarzakh"
+	SpaceJSpace  Space = "j-space"
+)
+
+// Note explains the rules of a special space to a pilot, or returns "" for normal space.
+func (s Space) Note() string {
+	switch s {
+	case SpacePochven:
+		return "Pochven: its gates only link Pochven systems. The way in or out is by wormhole or filament."
+	case SpaceZarzakh:
+		return "Zarzakh: after gating in you can only leave by the same gate for 6 hours, so routes end here rather than pass through."
+	case SpaceJSpace:
+		return "Wormhole space: no stargates. The only way in or out is through a wormhole."
+	}
+	return ""
+}
+
+// SpaceOf returns the special space a system belongs to.
+func (g *Graph) SpaceOf(systemID int) Space {
+	switch {
+	case systemID == ZarzakhSystemID:
+		return SpaceZarzakh
+	case IsJSpace(systemID):
+		return SpaceJSpace
+	case g.region[systemID] == pochvenRegionID:
+		return SpacePochven
+	}
+	return SpaceNormal
+}
+
+// noTransit reports whether a search may not carry on out of the system at
+// dense index u. Zarzakh's gate lockout means a route can start or end
+// there, but can't enter by one gate and leave by another. endpoint is the
+// system the search started from, which is always free to leave.
+func (s *Snapshot) noTransit(u, endpoint int32) bool {
+	return u == s.graph.zarzakh && u != endpoint
+}
@@ -0,0 +1,191 @@
+// internal/routing/waypoints.go
+package routing
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Above this many intermediate waypoints OptimiseOrder stops solving the
+// order exactly and falls back to nearest-neighbour plus 2-opt.
+const maxExactWaypoints = 10
+
+// NoRouteError reports the leg of a route that could not be completed.
+type NoRouteError struct {
+	From, To int
+}
+
+func (e *NoRouteError) Error() string {
+	return fmt.Sprintf("no route from system %d to system %d", e.From, e.To)
+}
+
+// PlanWaypoints routes through each waypoint in order and returns the systems
+// of every leg. Consecutive legs share their boundary system. A waypoint
+// repeated straight after itself is only visited once, so every leg has at
+// least one jump unless the whole route starts and ends in one system.
+func (s *Snapshot) PlanWaypoints(waypoints []int, opts RouteOptions) ([][]int, error) {
+	if len(waypoints) < 2 {
+		return nil, fmt.Errorf("need at least two waypoints, got %d", len(waypoints))
+	}
+	waypoints = slices.Compact(slices.Clone(waypoints))
+	if len(waypoints) == 1 {
+		return [][]int{{waypoints[0]}}, nil
+	}
+	legs := make([][]int, 0, len(waypoints)-1)
+	for i := 0; i+1 < len(waypoints); i++ {
+		from, to := waypoints[i], waypoints[i+1]
+		leg := s.Path(from, to, opts)
+		if leg == nil {
+			return nil, &NoRouteError{From: from, To: to}
+		}
+		legs = append(legs, leg)
+	}
+	return legs, nil
+}
+
+// OptimiseOrder reorders the waypoints between the first and the last so the
+// total route cost is as low as possible. The first and last stay fixed.
+func (s *Snapshot) OptimiseOrder(waypoints []int, opts RouteOptions) ([]int, error) {
+	if len(waypoints) <= 3 {
+		return waypoints, nil
+	}
+
+	// Pairwise costs, one full search per waypoint.
+	n := len(waypoints)
+	cost := make([][]int, n)
+	for i, from := range waypoints {
+		result := s.ShortestPath(from, -1, opts)
+		cost[i] = make([]int, n)
+		for j, to := range waypoints {
+			d, ok := result.Dist(to)
+			if !ok {
+				result.Release()
+				return nil, &NoRouteError{From: from, To: to}
+			}
+			cost[i][j] = d
+		}
+		result.Release()
+	}
+
+	var order []int
+	if n-2 <= maxExactWaypoints {
+		order = exactOrder(cost)
+	} else {
+		order = twoOpt(cost, nearestNeighbourOrder(cost))
+	}
+
+	out := make([]int, n)
+	for i, idx := range order {
+		out[i] = waypoints[idx]
+	}
+	return out, nil
+}
+
+// exactOrder solves the fixed-endpoint path with Held-Karp over the
+// intermediate waypoints. It returns waypoint indexes in visiting order.
+func exactOrder(cost [][]int) []int {
+	const INF = int(1e9)
+	n := len(cost)
+	m := n - 2 // intermediates are indexes 1..n-2
+	full := 1 << m
+
+	// best[mask][k] is the cheapest cost of leaving 0, visiting exactly the
+	// intermediates in mask and ending on intermediate k.
+	best := make([][]int, full)
+	parent := make([][]int, full)
+	for mask := range best {
+		best[mask] = make([]int, m)
+		parent[mask] = make([]int, m)
+		for k := range best[mask] {
+			best[mask][k] = INF
+		}
+	}
+	for k := 0; k < m; k++ {
+		best[1<<k][k] = cost[0][k+1]
+		parent[1<<k][k] = -1
+	}
+	for mask := 1; mask < full; mask++ {
+		for k := 0; k < m; k++ {
+			if mask&(1<<k) == 0 || best[mask][k] == INF {
+				continue
+			}
+			for next := 0; next < m; next++ {
+				if mask&(1<<next) != 0 {
+					continue
+				}
+				nm := mask | 1<<next
+				if c := best[mask][k] + cost[k+1][next+1]; c < best[nm][next] {
+					best[nm][next] = c
+					parent[nm][next] = k
+				}
+			}
+		}
+	}
+
+	last, total := 0, INF
+	for k := 0; k < m; k++ {
+		if c := best[full-1][k] + cost[k+1][n-1]; c < total {
+			last, total = k, c
+		}
+	}
+
+	order := make([]int, n)
+	order[0], order[n-1] = 0, n-1
+	mask := full - 1
+	for pos := m; pos >= 1; pos-- {
+		order[pos] = last + 1
+		prevK := parent[mask][last]
+		mask &^= 1 << last
+		last = prevK
+	}
+	return order
+}
+
+// nearestNeighbourOrder greedily visits the closest unvisited intermediate next.
+func nearestNeighbourOrder(cost [][]int) []int {
+	n := len(cost)
+	visited := make([]bool, n)
+	order := []int{0}
+	visited[0], visited[n-1] = true, true
+	for len(order) < n-1 {
+		cur, next := order[len(order)-1], -1
+		for j := 1; j < n-1; j++ {
+			if !visited[j] && (next == -1 || cost[cur][j] < cost[cur][next]) {
+				next = j
+			}
+		}
+		visited[next] = true
+		order = append(order, next)
+	}
+	return append(order, n-1)
+}
+
+// twoOpt improves an order by reversing segments of intermediates while that
+// makes the route cheaper. Costs may be asymmetric, so each reversal is
+// priced in full rather than by its two end edges.
+func twoOpt(cost [][]int, order []int) []int {
+	total := func(o []int) int {
+		sum := 0
+		for i := 0; i+1 < len(o); i++ {
+			sum += cost[o[i]][o[i+1]]
+		}
+		return sum
+	}
+
+	best := total(order)
+	for improved := true; improved; {
+		improved = false
+		for i := 1; i < len(order)-2; i++ {
+			for j := i + 1; j < len(order)-1; j++ {
+				candidate := append([]int(nil), order...)
+				for a, b := i, j; a < b; a, b = a+1, b-1 {
+					candidate[a], candidate[b] = candidate[b], candidate[a]
+				}
+				if c := total(candidate); c < best {
+					order, best, improved = candidate, c, true
+				}
+			}
+		}
+	}
+	return order
+}
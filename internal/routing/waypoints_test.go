@@ -0,0 +1,167 @@
+package routing
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+const (
+	perimeterID   = 30000144
+	newCaldariID  = 30000145
+	amarrID       = 30002187
+	dodixieID     = 30002659
+	rensID        = 30002510
+	unreachableJS = 31000001 // J-space, no stargates
+)
+
+func TestPlanWaypoints(t *testing.T) {
+	snap := loadTestGraph(t).Snapshot()
+	opts := RouteOptions{Profile: ProfileShortest}
+
+	tests := []struct {
+		name      string
+		waypoints []int
+		legs      int
+		wantErr   bool
+	}{
+		{"plain", []int{jitaID, amarrID}, 1, false},
+		{"via", []int{jitaID, perimeterID, newCaldariID}, 2, false},
+		{"repeated waypoint", []int{jitaID, perimeterID, perimeterID, newCaldariID}, 2, false},
+		{"same start and end", []int{jitaID, jitaID}, 1, false},
+		{"one waypoint", []int{jitaID}, 0, true},
+		{"unreachable", []int{jitaID, unreachableJS}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			legs, err := snap.PlanWaypoints(tt.waypoints, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %t", err, tt.wantErr)
+			}
+			if len(legs) != tt.legs {
+				t.Fatalf("got %d legs, want %d", len(legs), tt.legs)
+			}
+			for i, leg := range legs {
+				if len(leg) == 0 {
+					t.Fatalf("leg %d is empty", i)
+				}
+				if i > 0 && leg[0] != legs[i-1][len(legs[i-1])-1] {
+					t.Errorf("leg %d starts at %d, previous leg ends at %d", i, leg[0], legs[i-1][len(legs[i-1])-1])
+				}
+			}
+			if len(legs) > 0 {
+				if first, last := legs[0][0], legs[len(legs)-1][len(legs[len(legs)-1])-1]; first != tt.waypoints[0] || last != tt.waypoints[len(tt.waypoints)-1] {
+					t.Errorf("route runs %d→%d, want %d→%d", first, last, tt.waypoints[0], tt.waypoints[len(tt.waypoints)-1])
+				}
+			}
+		})
+	}
+
+	var noRoute *NoRouteError
+	if _, err := snap.PlanWaypoints([]int{jitaID, unreachableJS}, opts); !errors.As(err, &noRoute) || noRoute.To != unreachableJS {
+		t.Errorf("error = %v, want a NoRouteError to %d", err, unreachableJS)
+	}
+}
+
+func TestOptimiseOrder(t *testing.T) {
+	snap := loadTestGraph(t).Snapshot()
+	opts := RouteOptions{Profile: ProfileShortest}
+	routeCost := func(waypoints []int) int {
+		legs, err := snap.PlanWaypoints(waypoints, opts)
+		if err != nil {
+			t.Fatalf("PlanWaypoints(%v): %v", waypoints, err)
+		}
+		total := 0
+		for _, leg := range legs {
+			total += snap.PathCost(leg, opts)
+		}
+		return total
+	}
+
+	tests := []struct {
+		name      string
+		waypoints []int
+		wantErr   bool
+	}{
+		{"too few to reorder", []int{jitaID, amarrID, dodixieID}, false},
+		{"badly ordered", []int{jitaID, amarrID, perimeterID, dodixieID, newCaldariID, rensID}, false},
+		{"repeated waypoint", []int{jitaID, perimeterID, amarrID, perimeterID, rensID}, false},
+		{"unreachable", []int{jitaID, amarrID, unreachableJS, rensID}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := snap.OptimiseOrder(tt.waypoints, opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got[0] != tt.waypoints[0] || got[len(got)-1] != tt.waypoints[len(tt.waypoints)-1] {
+				t.Errorf("endpoints moved: %v", got)
+			}
+			if a, b := slices.Sorted(slices.Values(got)), slices.Sorted(slices.Values(tt.waypoints)); !slices.Equal(a, b) {
+				t.Errorf("got waypoints %v, want a reordering of %v", got, tt.waypoints)
+			}
+			if c, given := routeCost(got), routeCost(tt.waypoints); c > given {
+				t.Errorf("optimised order costs %d, more than the given order's %d", c, given)
+			}
+		})
+	}
+}
+
+// TestExactOrder checks Held-Karp against trying every order, on random
+// asymmetric cost matrices.
+func TestExactOrder(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	orderCost := func(cost [][]int, order []int) int {
+		total := 0
+		for i := 0; i+1 < len(order); i++ {
+			total += cost[order[i]][order[i+1]]
+		}
+		return total
+	}
+	for _, n := range []int{3, 4, 5, 6, 7, 8} {
+		cost := make([][]int, n)
+		for i := range cost {
+			cost[i] = make([]int, n)
+			for j := range cost[i] {
+				if i != j {
+					cost[i][j] = 1 + rng.IntN(20)
+				}
+			}
+		}
+
+		// Every permutation of the intermediates 1..n-2.
+		best := -1
+		mid := make([]int, n-2)
+		for i := range mid {
+			mid[i] = i + 1
+		}
+		var permute func(k int)
+		permute = func(k int) {
+			if k == len(mid) {
+				order := append(append([]int{0}, mid...), n-1)
+				if c := orderCost(cost, order); best < 0 || c < best {
+					best = c
+				}
+				return
+			}
+			for i := k; i < len(mid); i++ {
+				mid[k], mid[i] = mid[i], mid[k]
+				permute(k + 1)
+				mid[k], mid[i] = mid[i], mid[k]
+			}
+		}
+		permute(0)
+
+		order := exactOrder(cost)
+		if order[0] != 0 || order[n-1] != n-1 {
+			t.Errorf("n=%d: endpoints moved: %v", n, order)
+		}
+		if got := orderCost(cost, order); got != best {
+			t.Errorf("n=%d: order %v costs %d, best is %d", n, order, got, best)
+		}
+	}
+}
@@ -1,16 +1,126 @@
 // internal/routing/wormholes.go
 package routing
 
+import (
+	"strings"
+	"time"
+)
+
+// A hole with less than this long left is end of life.
+const eolWindow = 4 * time.Hour
+
+// The two hubs EVE-Scout publishes connections for.
+const (
+	TheraSystemID  = 31000005
+	TurnurSystemID = 30002086
+)
+
+// IsJSpace reports whether a system ID is in wormhole space.
+func IsJSpace(systemID int) bool {
+	return systemID >= 31000000 && systemID < 32000000
+}
+
 type WHLink struct {
 	From int
 	To   int
 	Cost int
+	Kind EdgeKind // KindWormhole when left empty
+
+	Source    string    // where the connection came from, e.g. "Wingspan" or "EVE-Scout"
+	Scout     string    // who scanned or last updated it
+	UpdatedAt time.Time // zero when unknown
+
+	Type        string   // wormhole type code, e.g. "B274"; empty or "K162" when unknown
+	MaxShipSize ShipSize // SizeUnknown when neither the type nor the source says
+	Mass        string   // Tripwire mass state: "stable", "destab" or "critical"
+	Life        string   // Tripwire life state: "stable" or "critical" (end of life)
+
+	FromSignature string    // signature ID in the From system
+	ToSignature   string    // signature ID in the To system
+	CreatedAt     time.Time // zero when unknown
+	ExpiresAt     time.Time // zero when unknown
+}
+
+// MassCritical reports whether the hole is close to collapsing.
+func (l *WHLink) MassCritical() bool {
+	return strings.EqualFold(l.Mass, "critical")
+}
+
+// EOL reports whether the hole is end of life, either flagged by the
+// scout or inside the last few hours of its expiry.
+func (l *WHLink) EOL(now time.Time) bool {
+	if strings.EqualFold(l.Life, "critical") {
+		return true
+	}
+	return !l.ExpiresAt.IsZero() && l.ExpiresAt.Sub(now) < eolWindow
+}
+
+// HoursLeft returns the whole hours until the hole expires, or -1 when unknown.
+func (l *WHLink) HoursLeft(now time.Time) int {
+	if l.ExpiresAt.IsZero() {
+		return -1
+	}
+	if left := l.ExpiresAt.Sub(now); left > 0 {
+		return int(left.Hours())
+	}
+	return 0
+}
+
+// WormholeFilter drops holes a pilot doesn't want to risk. The zero value keeps everything.
+type WormholeFilter struct {
+	IgnoreEOL      bool
+	IgnoreCritical bool
+	MinHoursLeft   int           // 0 disables the check
+	MaxAge         time.Duration // 0 disables the check
+}
+
+// Allows reports whether a hole passes the filter. Holes with an unknown
+// expiry or age are kept, since there is nothing to judge them by.
+func (f WormholeFilter) Allows(l *WHLink, now time.Time) bool {
+	if f.IgnoreEOL && l.EOL(now) {
+		return false
+	}
+	if f.IgnoreCritical && l.MassCritical() {
+		return false
+	}
+	if f.MinHoursLeft > 0 {
+		if left := l.HoursLeft(now); left >= 0 && left < f.MinHoursLeft {
+			return false
+		}
+	}
+	if f.MaxAge > 0 && !l.CreatedAt.IsZero() && now.Sub(l.CreatedAt) > f.MaxAge {
+		return false
+	}
+	return true
+}
+
+// UpdateWormholes publishes links as the graph's new overlay, alongside the
+// jump bridges already loaded. Snapshots already taken keep the overlay they
+// started with. It returns false, and publishes nothing, when the links are
+// the same as the current overlay's.
+func (g *Graph) UpdateWormholes(links []WHLink) bool {
+	return g.publish(func(current []WHLink) []WHLink {
+		next := append([]WHLink(nil), links...)
+		for _, l := range current {
+			if l.Kind == KindBridge {
+				next = append(next, l)
+			}
+		}
+		return next
+	})
 }
 
-func (g *Graph) UpdateWormholes(links []WHLink) {
-	g.dynamicAdj = make(map[int][]Edge)
-	for _, l := range links {
-		g.dynamicAdj[l.From] = append(g.dynamicAdj[l.From], Edge{To: l.To, Weight: l.Cost})
-		g.dynamicAdj[l.To] = append(g.dynamicAdj[l.To], Edge{To: l.From, Weight: l.Cost})
+// publish builds the next overlay from the current one's links and swaps it
+// in if anything changed. Writers are serialised so that a wormhole refresh
+// and a bridge update can't each drop the other's links.
+func (g *Graph) publish(update func(current []WHLink) []WHLink) bool {
+	g.publishMu.Lock()
+	defer g.publishMu.Unlock()
+	current := g.overlay.Load()
+	next := g.newOverlay(update(current.links))
+	if current.Version() == next.Version() {
+		return false
 	}
+	g.overlay.Store(next)
+	return true
 }
@@ -0,0 +1,44 @@
+package routing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWormholeFilterAllows(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	fresh := WHLink{CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(15 * time.Hour)}
+	eolSoon := WHLink{ExpiresAt: now.Add(3 * time.Hour)}
+	flaggedEOL := WHLink{Life: "critical", ExpiresAt: now.Add(10 * time.Hour)}
+	critical := WHLink{Mass: "critical"}
+	old := WHLink{CreatedAt: now.Add(-20 * time.Hour)}
+	unknown := WHLink{}
+
+	tests := []struct {
+		name   string
+		filter WormholeFilter
+		link   WHLink
+		want   bool
+	}{
+		{"zero filter keeps EOL", WormholeFilter{}, flaggedEOL, true},
+		{"zero filter keeps critical", WormholeFilter{}, critical, true},
+		{"ignore EOL drops flagged hole", WormholeFilter{IgnoreEOL: true}, flaggedEOL, false},
+		{"ignore EOL drops hole near expiry", WormholeFilter{IgnoreEOL: true}, eolSoon, false},
+		{"ignore EOL keeps fresh hole", WormholeFilter{IgnoreEOL: true}, fresh, true},
+		{"ignore critical drops critical hole", WormholeFilter{IgnoreCritical: true}, critical, false},
+		{"ignore critical keeps fresh hole", WormholeFilter{IgnoreCritical: true}, fresh, true},
+		{"min hours drops short-lived hole", WormholeFilter{MinHoursLeft: 4}, eolSoon, false},
+		{"min hours keeps long-lived hole", WormholeFilter{MinHoursLeft: 4}, fresh, true},
+		{"min hours keeps unknown expiry", WormholeFilter{MinHoursLeft: 4}, unknown, true},
+		{"max age drops old hole", WormholeFilter{MaxAge: 12 * time.Hour}, old, false},
+		{"max age keeps new hole", WormholeFilter{MaxAge: 12 * time.Hour}, fresh, true},
+		{"max age keeps unknown age", WormholeFilter{MaxAge: 12 * time.Hour}, unknown, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Allows(&tt.link, now); got != tt.want {
+				t.Errorf("Allows = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,361 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"wingspan-ops/internal/models"
+)
+
+const (
+	// accountsPath is where the characters linked to each account are saved.
+	accountsPath = "accounts.json"
+	// membershipTTL is how long a character's corporation is trusted before
+	// it is looked up again.
+	membershipTTL = time.Hour
+	// membershipCheckInterval is how often stale characters are looked for.
+	membershipCheckInterval = time.Minute
+)
+
+// accountList groups characters into accounts, so a pilot logged in on one
+// character can act as any of their alts. Accounts are keyed by a random ID
+// kept in the session.
+type accountList struct {
+	mu       sync.Mutex
+	path     string
+	accounts map[string][]models.LinkedCharacter
+}
+
+// load reads the saved accounts.
+func (l *accountList) load() error {
+	accounts := make(map[string][]models.LinkedCharacter)
+	if _, err := loadJSON(l.path, &accounts); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.accounts = accounts
+	l.mu.Unlock()
+	return nil
+}
+
+// save writes the accounts. The caller must hold l.mu.
+func (l *accountList) save() error {
+	return saveJSON(l.path, l.accounts)
+}
+
+// accountOf returns the account a character is linked to.
+func (l *accountList) accountOf(charID int) (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for id, chars := range l.accounts {
+		for _, c := range chars {
+			if c.ID == charID {
+				return id, true
+			}
+		}
+	}
+	return "", false
+}
+
+// characters returns a copy of the characters linked to an account.
+func (l *accountList) characters(accountID string) []models.LinkedCharacter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]models.LinkedCharacter(nil), l.accounts[accountID]...)
+}
+
+// link adds a character to an account, or updates it if it is already there.
+// Having just proven ownership through SSO, a character linked to another
+// account is moved off it.
+func (l *accountList) link(accountID string, char models.LinkedCharacter) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for id, chars := range l.accounts {
+		for i, c := range chars {
+			if c.ID != char.ID {
+				continue
+			}
+			if id == accountID {
+				char.LinkedAt = c.LinkedAt
+				chars[i] = char
+				return l.save()
+			}
+			l.accounts[id] = append(chars[:i], chars[i+1:]...)
+			if len(l.accounts[id]) == 0 {
+				delete(l.accounts, id)
+			}
+			break
+		}
+	}
+	l.accounts[accountID] = append(l.accounts[accountID], char)
+	return l.save()
+}
+
+// update replaces the saved details of characters already on an account.
+func (l *accountList) update(accountID string, chars []models.LinkedCharacter) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, char := range chars {
+		for i, c := range l.accounts[accountID] {
+			if c.ID == char.ID {
+				l.accounts[accountID][i] = char
+			}
+		}
+	}
+	return l.save()
+}
+
+// updateAll replaces the saved details of characters on any account.
+func (l *accountList) updateAll(chars []models.LinkedCharacter) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	byID := make(map[int]models.LinkedCharacter, len(chars))
+	for _, c := range chars {
+		byID[c.ID] = c
+	}
+	for _, list := range l.accounts {
+		for i, c := range list {
+			if char, ok := byID[c.ID]; ok {
+				list[i] = char
+			}
+		}
+	}
+	return l.save()
+}
+
+// stale returns a copy of every linked character last checked longer than ttl ago.
+func (l *accountList) stale(ttl time.Duration) []models.LinkedCharacter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var chars []models.LinkedCharacter
+	for _, list := range l.accounts {
+		for _, c := range list {
+			if time.Since(c.CheckedAt) >= ttl {
+				chars = append(chars, c)
+			}
+		}
+	}
+	return chars
+}
+
+// allowed reports whether a character on an account may keep using the hub.
+// known is false for a character on no account, whose access is unknown.
+func (l *accountList) allowed(charID int) (allowed, known bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, list := range l.accounts {
+		for _, c := range list {
+			if c.ID == charID {
+				return c.Member, true
+			}
+		}
+	}
+	return false, false
+}
+
+// unlink removes a character from an account.
+func (l *accountList) unlink(accountID string, charID int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	chars := l.accounts[accountID]
+	for i, c := range chars {
+		if c.ID == charID {
+			l.accounts[accountID] = append(chars[:i], chars[i+1:]...)
+			return l.save()
+		}
+	}
+	return errors.New("that character isn't linked to your account")
+}
+
+// LoadAccounts reads the saved accounts and their linked characters.
+func (s *Server) LoadAccounts() error {
+	if err := s.accounts.load(); err != nil {
+		return err
+	}
+	log.Printf("✅ Loaded %d accounts.", len(s.accounts.accounts))
+	return nil
+}
+
+// accountID returns the logged-in pilot's account ID from the session.
+func (s *Server) accountID(r *http.Request) (string, bool) {
+	session, err := s.sessionStore.Get(r, sessionName)
+	if err != nil {
+		return "", false
+	}
+	id, ok := session.Values[sessionAccountKey].(string)
+	return id, ok && id != ""
+}
+
+// linkedCharacters returns the characters on the logged-in pilot's account,
+// with the active one marked, for the character switcher.
+func (s *Server) linkedCharacters(r *http.Request) []models.LinkedCharacter {
+	accountID, ok := s.accountID(r)
+	if !ok {
+		return nil
+	}
+	active, _ := s.characterID(r)
+	chars := s.accounts.characters(accountID)
+	for i := range chars {
+		chars[i].Active = chars[i].ID == active
+	}
+	return chars
+}
+
+// newAccountID makes a random ID for a new account.
+func newAccountID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// refreshCharacters re-checks any linked character whose membership is
+// stale, in one batched call.
+func (s *Server) refreshCharacters(ctx context.Context, accountID string, chars []models.LinkedCharacter) []models.LinkedCharacter {
+	var stale []int
+	var check []models.LinkedCharacter
+	for i, c := range chars {
+		if time.Since(c.CheckedAt) >= membershipTTL {
+			stale = append(stale, i)
+			check = append(check, c)
+		}
+	}
+	if len(check) == 0 {
+		return chars
+	}
+	if err := s.checkCharacters(ctx, check); err != nil {
+		log.Printf("WARN: Membership check failed for account %s: %v", accountID, err)
+		return chars
+	}
+	for j, i := range stale {
+		chars[i] = check[j]
+	}
+	if err := s.accounts.update(accountID, check); err != nil {
+		log.Printf("ERROR: Failed to save accounts: %v", err)
+	}
+	return chars
+}
+
+// accountHandler lists the characters linked to the pilot's account and
+// switches or unlinks them.
+func (s *Server) accountHandler(w http.ResponseWriter, r *http.Request) {
+	session, err := s.sessionStore.Get(r, sessionName)
+	if err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	accountID, ok := session.Values[sessionAccountKey].(string)
+	if !ok || accountID == "" {
+		// Sessions from before accounts existed get one for their character.
+		if accountID, err = s.startAccount(r); err != nil {
+			log.Printf("ERROR: Failed to create account: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		session.Values[sessionAccountKey] = accountID
+		if err := session.Save(r, w); err != nil {
+			log.Printf("ERROR: Failed to save session: %v", err)
+		}
+	}
+
+	if r.Method == http.MethodPost {
+		charID, _ := strconv.Atoi(r.FormValue("character_id"))
+		switch r.FormValue("action") {
+		case "switch":
+			err = s.switchCharacter(w, r, accountID, charID)
+		case "unlink":
+			if active, _ := s.characterID(r); charID == active {
+				err = errors.New("switch to another character before unlinking this one")
+			} else if err = s.accounts.unlink(accountID, charID); err == nil {
+				if err := s.tokens.revoke(r.Context(), charID); err != nil {
+					log.Printf("WARN: Failed to revoke SSO token for char ID %d: %v", charID, err)
+				}
+			}
+		default:
+			err = fmt.Errorf("unknown action %q", r.FormValue("action"))
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		back := r.FormValue("return_to")
+		if back == "" || back[0] != '/' || (len(back) > 1 && (back[1] == '/' || back[1] == '\\')) {
+			back = "/account"
+		}
+		http.Redirect(w, r, back, http.StatusSeeOther)
+		return
+	}
+
+	chars := s.refreshCharacters(r.Context(), accountID, s.accounts.characters(accountID))
+	active, _ := s.characterID(r)
+	for i := range chars {
+		chars[i].Active = chars[i].ID == active
+	}
+	data := models.FrontendData{
+		FeedbackURL:   s.feedbackURL,
+		CharacterName: s.getAuthenticatedUser(r),
+		Characters:    chars,
+		WatchNotices:  s.watchNotices(r),
+	}
+	ts, ok := s.templates["account.html"]
+	if !ok {
+		http.Error(w, "Could not load account.html template", http.StatusInternalServerError)
+		return
+	}
+	if err := ts.Execute(w, data); err != nil {
+		log.Printf("Template execution error: %v", err)
+	}
+}
+
+// startAccount creates an account holding just the logged-in character.
+func (s *Server) startAccount(r *http.Request) (string, error) {
+	charID, ok := s.characterID(r)
+	if !ok {
+		return "", errors.New("no character in session")
+	}
+	if id, ok := s.accounts.accountOf(charID); ok {
+		return id, nil
+	}
+	id, err := newAccountID()
+	if err != nil {
+		return "", err
+	}
+	// The character passed the access check when it logged in; if it can't
+	// be checked again now, it keeps access until the next check.
+	chars := []models.LinkedCharacter{{ID: charID, Name: s.getAuthenticatedUser(r), Member: true, LinkedAt: time.Now()}}
+	if err := s.checkCharacters(r.Context(), chars); err != nil {
+		log.Printf("WARN: Membership check failed for char ID %d: %v", charID, err)
+	}
+	return id, s.accounts.link(id, chars[0])
+}
+
+// switchCharacter makes another character on the account the one the session
+// acts as. Only characters that are members may be switched to.
+func (s *Server) switchCharacter(w http.ResponseWriter, r *http.Request, accountID string, charID int) error {
+	chars := s.refreshCharacters(r.Context(), accountID, s.accounts.characters(accountID))
+	for _, c := range chars {
+		if c.ID != charID {
+			continue
+		}
+		if !c.Member {
+			return fmt.Errorf("%s is not allowed by the access list", c.Name)
+		}
+		session, _ := s.sessionStore.Get(r, sessionName)
+		session.Values[sessionCharIDKey] = c.ID
+		session.Values[sessionCharNameKey] = c.Name
+		if err := session.Save(r, w); err != nil {
+			return err
+		}
+		log.Printf("Switched character: %s (ID: %d)", c.Name, c.ID)
+		return nil
+	}
+	return errors.New("that character isn't linked to your account")
+}
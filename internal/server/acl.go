@@ -0,0 +1,323 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"wingspan-ops/internal/models"
+)
+
+// accessControl holds the access list deciding who may use the hub and keeps it on disk.
+type accessControl struct {
+	mu   sync.RWMutex
+	path string
+	acl  models.AccessList
+}
+
+// defaultAccessList lets in WINGSPAN only, as the hub did before the access
+// list could be configured. Its name is filled in from ESI on load.
+func defaultAccessList() models.AccessList {
+	return models.AccessList{
+		Allow: models.AccessRules{Corporations: []models.AccessEntry{{ID: wingspanCorpID}}},
+	}
+}
+
+// load reads the saved access list. Without a file the default list is used.
+func (a *accessControl) load() error {
+	var acl models.AccessList
+	found, err := loadJSON(a.path, &acl)
+	if err != nil {
+		return err
+	}
+	if !found {
+		acl = defaultAccessList()
+	}
+	a.set(acl)
+	return nil
+}
+
+// save writes the access list.
+func (a *accessControl) save() error {
+	return saveJSON(a.path, a.list())
+}
+
+func (a *accessControl) list() models.AccessList {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.acl
+}
+
+func (a *accessControl) set(acl models.AccessList) {
+	a.mu.Lock()
+	a.acl = acl
+	a.mu.Unlock()
+}
+
+// LoadAccessList reads the access list, filling in the names of any entries
+// given only by ID.
+func (s *Server) LoadAccessList() error {
+	if err := s.access.load(); err != nil {
+		return err
+	}
+	acl := s.access.list()
+	s.nameEntries(context.Background(), &acl)
+	s.access.set(acl)
+	allow, deny := acl.Allow, acl.Deny
+	log.Printf("✅ Loaded access list: %d allowed and %d denied entries.",
+		len(allow.Characters)+len(allow.Corporations)+len(allow.Alliances),
+		len(deny.Characters)+len(deny.Corporations)+len(deny.Alliances))
+	return nil
+}
+
+// accessHandler shows the access list and lets admins replace it.
+func (s *Server) accessHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		if !s.isAdmin(r) {
+			http.Error(w, "Only admins can edit the access list.", http.StatusForbidden)
+			return
+		}
+		acl, err := s.accessListFromForm(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.access.set(acl)
+		if err := s.access.save(); err != nil {
+			log.Printf("ERROR: Failed to save access list: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		s.applyAccessList()
+		log.Printf("Access list replaced by %s.", s.getAuthenticatedUser(r))
+		http.Redirect(w, r, "/access", http.StatusSeeOther)
+		return
+	}
+
+	data := models.FrontendData{
+		FeedbackURL:   s.feedbackURL,
+		CharacterName: s.getAuthenticatedUser(r),
+		Characters:    s.linkedCharacters(r),
+		AccessList:    s.access.list(),
+		IsAdmin:       s.isAdmin(r),
+	}
+	ts, ok := s.templates["access.html"]
+	if !ok {
+		http.Error(w, "Could not load access.html template", http.StatusInternalServerError)
+		return
+	}
+	if err := ts.Execute(w, data); err != nil {
+		log.Printf("Template execution error: %v", err)
+	}
+}
+
+// accessListFromForm reads the six lists on the access page, one name or ID
+// per line, and resolves the names with a single ESI call.
+func (s *Server) accessListFromForm(r *http.Request) (models.AccessList, error) {
+	fields := []string{
+		"allow_characters", "allow_corporations", "allow_alliances",
+		"deny_characters", "deny_corporations", "deny_alliances",
+	}
+	lines := make(map[string][]string, len(fields))
+	var names []string
+	for _, field := range fields {
+		for _, line := range strings.Split(r.FormValue(field), "\n") {
+			if line = strings.TrimSpace(line); line == "" {
+				continue
+			}
+			lines[field] = append(lines[field], line)
+			if _, err := strconv.Atoi(line); err != nil {
+				names = append(names, line)
+			}
+		}
+	}
+	ids, err := s.esiClient.GetEntityIDs(r.Context(), names)
+	if err != nil {
+		return models.AccessList{}, fmt.Errorf("could not look up names: %w", err)
+	}
+
+	var unknown []string
+	entries := func(field string, byName map[string]int) []models.AccessEntry {
+		var list []models.AccessEntry
+		for _, line := range lines[field] {
+			if id, err := strconv.Atoi(line); err == nil {
+				list = append(list, models.AccessEntry{ID: id})
+			} else if id, ok := byName[strings.ToLower(line)]; ok {
+				list = append(list, models.AccessEntry{ID: id, Name: line})
+			} else {
+				unknown = append(unknown, line)
+			}
+		}
+		return list
+	}
+	acl := models.AccessList{
+		Allow: models.AccessRules{
+			Characters:   entries("allow_characters", ids.Characters),
+			Corporations: entries("allow_corporations", ids.Corporations),
+			Alliances:    entries("allow_alliances", ids.Alliances),
+		},
+		Deny: models.AccessRules{
+			Characters:   entries("deny_characters", ids.Characters),
+			Corporations: entries("deny_corporations", ids.Corporations),
+			Alliances:    entries("deny_alliances", ids.Alliances),
+		},
+	}
+	if len(unknown) > 0 {
+		return models.AccessList{}, fmt.Errorf("could not find: %s", strings.Join(unknown, ", "))
+	}
+	if allow := acl.Allow; len(allow.Characters)+len(allow.Corporations)+len(allow.Alliances) == 0 {
+		return models.AccessList{}, errors.New("the allow list can't be empty; that would lock everyone out")
+	}
+	s.nameEntries(r.Context(), &acl)
+	return acl, nil
+}
+
+// nameEntries looks up the names of access list entries given only by ID.
+func (s *Server) nameEntries(ctx context.Context, acl *models.AccessList) {
+	lists := []*[]models.AccessEntry{
+		&acl.Allow.Characters, &acl.Allow.Corporations, &acl.Allow.Alliances,
+		&acl.Deny.Characters, &acl.Deny.Corporations, &acl.Deny.Alliances,
+	}
+	var ids []int
+	for _, list := range lists {
+		for _, e := range *list {
+			if e.Name == "" {
+				ids = append(ids, e.ID)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	slices.Sort(ids)
+	names, err := s.esiClient.GetNames(ctx, slices.Compact(ids))
+	if err != nil {
+		log.Printf("WARN: Failed to look up access list names: %v", err)
+		return
+	}
+	for _, list := range lists {
+		for i := range *list {
+			if e := &(*list)[i]; e.Name == "" {
+				e.Name = names[e.ID]
+			}
+		}
+	}
+}
+
+// checkCharacters looks up the corporation and alliance of every character
+// in one batched ESI call and applies the access list to them.
+func (s *Server) checkCharacters(ctx context.Context, chars []models.LinkedCharacter) error {
+	ids := make([]int, len(chars))
+	for i, c := range chars {
+		ids[i] = c.ID
+	}
+	affiliations, err := s.esiClient.GetAffiliations(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	var groupIDs []int
+	for _, a := range affiliations {
+		groupIDs = append(groupIDs, a.CorporationID)
+		if a.AllianceID != 0 {
+			groupIDs = append(groupIDs, a.AllianceID)
+		}
+	}
+	slices.Sort(groupIDs)
+	names, err := s.esiClient.GetNames(ctx, slices.Compact(groupIDs))
+	if err != nil {
+		log.Printf("WARN: Failed to look up corporation and alliance names: %v", err)
+	}
+
+	acl := s.access.list()
+	for i := range chars {
+		c := &chars[i]
+		a, ok := affiliations[c.ID]
+		if !ok {
+			// ESI leaves out characters that no longer exist.
+			log.Printf("WARN: ESI has no affiliation for char ID %d", c.ID)
+			c.Member, c.CheckedAt = false, time.Now()
+			continue
+		}
+		c.CorporationID, c.CorporationName = a.CorporationID, names[a.CorporationID]
+		c.AllianceID, c.AllianceName = a.AllianceID, names[a.AllianceID]
+		c.Member = acl.Allows(c.ID, c.CorporationID, c.AllianceID)
+		c.CheckedAt = time.Now()
+	}
+	return nil
+}
+
+// applyAccessList re-applies the access list to every linked character using
+// the affiliations already on record, so an edit takes effect straight away.
+func (s *Server) applyAccessList() {
+	acl := s.access.list()
+	s.accounts.mu.Lock()
+	defer s.accounts.mu.Unlock()
+	for _, chars := range s.accounts.accounts {
+		for i := range chars {
+			c := &chars[i]
+			if c.CorporationID == 0 {
+				continue // never checked; the next check will decide
+			}
+			if allowed := acl.Allows(c.ID, c.CorporationID, c.AllianceID); allowed != c.Member {
+				log.Printf("Access list change: %s (ID: %d) is now allowed=%t.", c.Name, c.ID, allowed)
+				c.Member = allowed
+			}
+		}
+	}
+	if err := s.accounts.save(); err != nil {
+		log.Printf("ERROR: Failed to save accounts: %v", err)
+	}
+}
+
+// StartMembershipChecker re-checks linked characters against the access list
+// in the background. It runs on its own ticker so a slow wormhole API can't
+// hold up taking access away.
+func (s *Server) StartMembershipChecker(wg *sync.WaitGroup) {
+	defer wg.Done()
+	log.Println("[MEMBERSHIP] Starting background membership checker...")
+	ticker := time.NewTicker(membershipCheckInterval)
+	defer ticker.Stop()
+	for {
+		s.recheckAccounts()
+		<-ticker.C
+	}
+}
+
+// recheckAccounts re-checks every linked character whose affiliation is
+// stale, across all accounts, in one batched call, so characters leaving an
+// allowed corporation lose access within membershipTTL.
+func (s *Server) recheckAccounts() {
+	stale := s.accounts.stale(membershipTTL)
+	if len(stale) == 0 {
+		return
+	}
+	if err := s.checkCharacters(context.Background(), stale); err != nil {
+		log.Printf("[MEMBERSHIP] WARN: Affiliation check failed: %v", err)
+		return
+	}
+	if err := s.accounts.updateAll(stale); err != nil {
+		log.Printf("[MEMBERSHIP] ERROR: Failed to save accounts: %v", err)
+	}
+}
+
+// accessLines formats access list entries for editing, one per line, by
+// name where it is known and by ID otherwise.
+func accessLines(entries []models.AccessEntry) string {
+	var b strings.Builder
+	for _, e := range entries {
+		if e.Name != "" {
+			b.WriteString(e.Name)
+		} else {
+			b.WriteString(strconv.Itoa(e.ID))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
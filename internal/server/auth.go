@@ -4,49 +4,72 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"time"
+	"wingspan-ops/internal/models"
+
+	"github.com/gorilla/sessions"
+	"golang.org/x/oauth2"
 )
 
 // --- Constants for configuration and clarity ---
 const (
-	// EVE API URLs
-	eveVerifyURL = "https://login.eveonline.com/oauth/verify"
-	esiCharURL   = "https://esi.evetech.net/latest/characters/%d/"
-
 	// Application-specific settings
 	sessionName        = "wingspan-session"
 	sessionStateKey    = "oauth_state"
 	sessionAuthKey     = "authenticated"
 	sessionCharNameKey = "character_name"
+	sessionCharIDKey   = "character_id"
+	sessionAccountKey  = "account_id"
+	sessionLinkKey     = "linking" // set while an SSO round trip is linking another character
 
-	// Wingspan Corporation ID
+	// Wingspan Corporation ID, the only corporation allowed until an access list is saved.
 	wingspanCorpID = 98330748
 )
 
 // --- Structs for decoding EVE API responses ---
 
+// EveVerifyResponse is the character an SSO access token was issued to.
 type EveVerifyResponse struct {
-	CharacterID   int    `json:"CharacterID"`
-	CharacterName string `json:"CharacterName"`
-}
-
-type EsiCharacterResponse struct {
-	CorporationID int `json:"corporation_id"`
+	CharacterID   int
+	CharacterName string
+	Scopes        []string // scopes the character granted
 }
 
 // --- HTTP Handlers ---
 
 // loginHandler starts the EVE SSO process.
 func (s *Server) loginHandler(w http.ResponseWriter, r *http.Request) {
+	s.startSSO(w, r, false)
+}
+
+// linkHandler starts an SSO round trip that adds another character to the
+// logged-in pilot's account instead of logging in as it.
+func (s *Server) linkHandler(w http.ResponseWriter, r *http.Request) {
+	s.startSSO(w, r, true)
+}
+
+// startSSO sends the user to EVE SSO, remembering whether they are linking a character.
+func (s *Server) startSSO(w http.ResponseWriter, r *http.Request, link bool) {
 	session, err := s.sessionStore.Get(r, sessionName)
 	if err != nil {
 		http.Error(w, "Failed to get session", http.StatusInternalServerError)
 		return
 	}
 
+	// Sessions from before accounts existed need one to link characters to.
+	if _, ok := session.Values[sessionAccountKey].(string); link && !ok {
+		accountID, err := s.startAccount(r)
+		if err != nil {
+			log.Printf("ERROR: Failed to create account: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		session.Values[sessionAccountKey] = accountID
+	}
+
 	// Generate a secure random state token
 	state, err := generateRandomState()
 	if err != nil {
@@ -56,6 +79,7 @@ func (s *Server) loginHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	session.Values[sessionStateKey] = state
+	session.Values[sessionLinkKey] = link
 	if err := session.Save(r, w); err != nil {
 		log.Printf("ERROR: Failed to save session: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -76,40 +100,94 @@ func (s *Server) callbackHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 2. Exchange the authorization code for a token and verify the character.
-	verifyResponse, err := s.verifyEveSSO(r.Context(), r.FormValue("code"))
+	verifyResponse, token, err := s.verifyEveSSO(r.Context(), r.FormValue("code"))
 	if err != nil {
 		log.Printf("ERROR: EVE SSO verification failed: %v", err)
 		http.Error(w, "Failed to verify EVE character", http.StatusInternalServerError)
 		return
 	}
 
-	// 3. Check if the character is a member of the required corporation.
-	isMember, err := s.isWingspanMember(verifyResponse.CharacterID)
-	if err != nil {
+	// 3. Check the character's corporation and alliance against the access list.
+	chars := []models.LinkedCharacter{{
+		ID:       verifyResponse.CharacterID,
+		Name:     verifyResponse.CharacterName,
+		LinkedAt: time.Now(),
+	}}
+	if err := s.checkCharacters(r.Context(), chars); err != nil {
 		log.Printf("ERROR: Corporation check failed for char ID %d: %v", verifyResponse.CharacterID, err)
 		http.Error(w, "Failed to check character's corporation", http.StatusInternalServerError)
 		return
 	}
-	if !isMember {
-		log.Printf("ACCESS DENIED: %s (ID: %d) is not in WINGSPAN.", verifyResponse.CharacterName, verifyResponse.CharacterID)
+	char := chars[0]
+	session, _ := s.sessionStore.Get(r, sessionName) // We can ignore this error as it was checked in validateState.
+
+	// Linking an alt adds it to the account whatever its corporation; it
+	// just can't be switched to unless the access list allows it.
+	if linking, _ := session.Values[sessionLinkKey].(bool); linking {
+		s.finishLink(w, r, session, char, token, verifyResponse.Scopes)
+		return
+	}
+	if !char.Member {
+		log.Printf("ACCESS DENIED: %s (ID: %d) is not allowed by the access list.", char.Name, char.ID)
 		http.Error(w, "Access Denied: This platform is for Wingspan members only.", http.StatusForbidden)
 		return
 	}
 
-	// 4. All checks passed. Log the user in by updating the session.
-	session, _ := s.sessionStore.Get(r, sessionName) // We can ignore this error as it was checked in validateState.
+	// 4. All checks passed. Keep the token for ESI calls made on the character's behalf,
+	// add the character to its account, then log the user in by updating the session.
+	if err := s.tokens.put(char.ID, token, verifyResponse.Scopes); err != nil {
+		log.Printf("ERROR: Failed to save SSO tokens: %v", err)
+	}
+	accountID, ok := s.accounts.accountOf(char.ID)
+	if !ok {
+		if accountID, err = newAccountID(); err != nil {
+			log.Printf("ERROR: Failed to create account: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := s.accounts.link(accountID, char); err != nil {
+		log.Printf("ERROR: Failed to save accounts: %v", err)
+	}
 	session.Values[sessionAuthKey] = true
-	session.Values[sessionCharNameKey] = verifyResponse.CharacterName
+	session.Values[sessionCharNameKey] = char.Name
+	session.Values[sessionCharIDKey] = char.ID
+	session.Values[sessionAccountKey] = accountID
 	if err := session.Save(r, w); err != nil {
 		log.Printf("ERROR: Failed to save final session: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	log.Printf("User logged in: %s (ID: %d)", verifyResponse.CharacterName, verifyResponse.CharacterID)
+	log.Printf("User logged in: %s (ID: %d)", char.Name, char.ID)
 	http.Redirect(w, r, "/", http.StatusFound)
 }
 
+// finishLink adds a character that has just been through SSO to the account
+// of the pilot who asked to link it.
+func (s *Server) finishLink(w http.ResponseWriter, r *http.Request, session *sessions.Session, char models.LinkedCharacter, token *oauth2.Token, scopes []string) {
+	session.Values[sessionLinkKey] = false
+	accountID, ok := session.Values[sessionAccountKey].(string)
+	if auth, _ := session.Values[sessionAuthKey].(bool); !auth || !ok {
+		http.Error(w, "Log in before linking another character.", http.StatusBadRequest)
+		return
+	}
+	if err := s.tokens.put(char.ID, token, scopes); err != nil {
+		log.Printf("ERROR: Failed to save SSO tokens: %v", err)
+	}
+	if err := s.accounts.link(accountID, char); err != nil {
+		log.Printf("ERROR: Failed to save accounts: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if err := session.Save(r, w); err != nil {
+		log.Printf("ERROR: Failed to save session: %v", err)
+	}
+
+	log.Printf("Character linked: %s (ID: %d) to account %s", char.Name, char.ID, accountID)
+	http.Redirect(w, r, "/account", http.StatusFound)
+}
+
 // logoutHandler clears the session and logs the user out.
 func (s *Server) logoutHandler(w http.ResponseWriter, r *http.Request) {
 	session, err := s.sessionStore.Get(r, sessionName)
@@ -119,6 +197,21 @@ func (s *Server) logoutHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Revoke the tokens of every character on the account, not just the active one.
+	charIDs := []int{}
+	if accountID, ok := session.Values[sessionAccountKey].(string); ok {
+		for _, c := range s.accounts.characters(accountID) {
+			charIDs = append(charIDs, c.ID)
+		}
+	} else if charID, ok := session.Values[sessionCharIDKey].(int); ok {
+		charIDs = append(charIDs, charID)
+	}
+	for _, charID := range charIDs {
+		if err := s.tokens.revoke(r.Context(), charID); err != nil {
+			log.Printf("WARN: Failed to revoke SSO token for char ID %d: %v", charID, err)
+		}
+	}
+
 	session.Values[sessionAuthKey] = false
 	session.Options.MaxAge = -1 // This effectively deletes the cookie.
 	session.Save(r, w)
@@ -141,12 +234,50 @@ func (s *Server) authMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		// A character can lose access after logging in, by leaving an allowed
+		// corporation or being denied on the access list.
+		charID, ok := session.Values[sessionCharIDKey].(int)
+		if !ok {
+			// Without a character the session is no use; end it so the
+			// login page doesn't send the pilot straight back here.
+			session.Values[sessionAuthKey] = false
+			session.Save(r, w)
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+		if !s.stillAllowed(w, r, session, charID) {
+			session.Values[sessionAuthKey] = false
+			session.Save(r, w)
+			http.Error(w, "Access Denied: This platform is for Wingspan members only.", http.StatusForbidden)
+			return
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
 
 // --- Helper Functions ---
 
+// stillAllowed reports whether the session's character may still use the hub.
+// A character on no account, from a session older than accounts, is given
+// one now so it is checked against the access list like everyone else.
+func (s *Server) stillAllowed(w http.ResponseWriter, r *http.Request, session *sessions.Session, charID int) bool {
+	if allowed, known := s.accounts.allowed(charID); known {
+		return allowed
+	}
+	accountID, err := s.startAccount(r)
+	if err != nil {
+		log.Printf("ERROR: Failed to create account for char ID %d: %v", charID, err)
+		return false
+	}
+	session.Values[sessionAccountKey] = accountID
+	if err := session.Save(r, w); err != nil {
+		log.Printf("ERROR: Failed to save session: %v", err)
+	}
+	allowed, _ := s.accounts.allowed(charID)
+	return allowed
+}
+
 // validateState checks the state token from the callback against the one in the session.
 func (s *Server) validateState(r *http.Request) error {
 	session, err := s.sessionStore.Get(r, sessionName)
@@ -161,51 +292,19 @@ func (s *Server) validateState(r *http.Request) error {
 	return nil
 }
 
-// verifyEveSSO handles the OAuth token exchange and fetches the character's identity.
-func (s *Server) verifyEveSSO(ctx context.Context, code string) (*EveVerifyResponse, error) {
+// verifyEveSSO handles the OAuth token exchange and reads the character's
+// identity from the access token, which is a JWT signed by EVE SSO.
+func (s *Server) verifyEveSSO(ctx context.Context, code string) (*EveVerifyResponse, *oauth2.Token, error) {
 	token, err := s.oauthConfig.Exchange(ctx, code)
 	if err != nil {
-		return nil, fmt.Errorf("failed to exchange token: %w", err)
+		return nil, nil, fmt.Errorf("failed to exchange token: %w", err)
 	}
 
-	client := s.oauthConfig.Client(ctx, token)
-	resp, err := client.Get(eveVerifyURL)
+	verifyResponse, err := s.verifyAccessToken(ctx, token.AccessToken)
 	if err != nil {
-		return nil, fmt.Errorf("failed to call verify endpoint: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("verify endpoint returned non-200 status: %s", resp.Status)
+		return nil, nil, fmt.Errorf("failed to validate access token: %w", err)
 	}
-
-	var verifyResponse EveVerifyResponse
-	if err := json.NewDecoder(resp.Body).Decode(&verifyResponse); err != nil {
-		return nil, fmt.Errorf("failed to decode verify response: %w", err)
-	}
-
-	return &verifyResponse, nil
-}
-
-// isWingspanMember checks if a character is part of the designated corporation.
-func (s *Server) isWingspanMember(characterID int) (bool, error) {
-	url := fmt.Sprintf(esiCharURL, characterID)
-	resp, err := http.Get(url)
-	if err != nil {
-		return false, fmt.Errorf("failed to call ESI character endpoint: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return false, fmt.Errorf("ESI endpoint returned non-200 status: %s", resp.Status)
-	}
-
-	var charResponse EsiCharacterResponse
-	if err := json.NewDecoder(resp.Body).Decode(&charResponse); err != nil {
-		return false, fmt.Errorf("failed to decode ESI character response: %w", err)
-	}
-
-	return charResponse.CorporationID == wingspanCorpID, nil
+	return verifyResponse, token, nil
 }
 
 // generateRandomState creates a cryptographically secure random string for the state token.
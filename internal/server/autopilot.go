@@ -0,0 +1,125 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"wingspan-ops/internal/routing"
+)
+
+// Autopilot modes, chosen with the mode form value.
+const (
+	autopilotDestination = "destination" // set the furthest reachable system as the destination
+	autopilotWaypoints   = "waypoints"   // clear the route and add every system as a waypoint
+)
+
+// autopilotHandler pushes a planned route into the character's game client.
+// The autopilot only follows stargates, so the route is cut short at the
+// first wormhole or jump bridge and the pilot takes that jump by hand.
+func (s *Server) autopilotHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	charID, ok := s.characterID(r)
+	if !ok {
+		writeMessage(w, http.StatusUnauthorized, "Log in to set your in-game route.")
+		return
+	}
+	systems, err := parseSystemIDs(r.FormValue("systems"))
+	if err != nil || len(systems) < 2 {
+		writeMessage(w, http.StatusBadRequest, "The route to send is missing or malformed.")
+		return
+	}
+	mode := r.FormValue("mode")
+	if mode != autopilotDestination && mode != autopilotWaypoints {
+		writeMessage(w, http.StatusBadRequest, fmt.Sprintf("Unknown autopilot mode %q.", mode))
+		return
+	}
+
+	reach, next := s.autopilotLeg(systems)
+	if len(reach) < 2 {
+		writeMessage(w, http.StatusOK, fmt.Sprintf("The first jump is through a %s to %s, which the autopilot can't take. Jump it by hand, then set the route again.",
+			next, s.esiClient.GetSystemName(systems[1])))
+		return
+	}
+
+	token, err := s.tokens.accessToken(r.Context(), charID, scopeWriteWaypoint)
+	if errors.Is(err, errNoToken) || errors.Is(err, errMissingScope) {
+		writeMessage(w, http.StatusUnauthorized, "Log in again and allow Short Circuit to set your in-game route.")
+		return
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to get access token for char ID %d: %v", charID, err)
+		writeMessage(w, http.StatusBadGateway, "Could not reach EVE SSO. Try again, or log in again.")
+		return
+	}
+
+	targets := reach[len(reach)-1:]
+	if mode == autopilotWaypoints {
+		targets = reach[1:]
+	}
+	for i, id := range targets {
+		if err := s.esiClient.SetAutopilotWaypoint(r.Context(), token, id, i == 0); err != nil {
+			log.Printf("ERROR: Failed to set waypoint %d for char ID %d: %v", id, charID, err)
+			writeMessage(w, http.StatusBadGateway, fmt.Sprintf("ESI refused the route after %d of %d waypoints.", i, len(targets)))
+			return
+		}
+	}
+
+	end := s.esiClient.GetSystemName(reach[len(reach)-1])
+	message := fmt.Sprintf("Destination set to %s.", end)
+	if mode == autopilotWaypoints {
+		message = fmt.Sprintf("Set %d waypoints to %s.", len(targets), end)
+	}
+	if len(reach) < len(systems) {
+		message += fmt.Sprintf(" The autopilot stops there: the next jump is through a %s to %s.",
+			next, s.esiClient.GetSystemName(systems[len(reach)]))
+	}
+	writeMessage(w, http.StatusOK, message)
+}
+
+// autopilotLeg returns the start of systems the autopilot can fly, up to and
+// including the system where the first non-stargate jump is taken, and what
+// kind of connection that jump is.
+func (s *Server) autopilotLeg(systems []int) ([]int, string) {
+	for i := 1; i < len(systems); i++ {
+		if s.graph.HasStargate(systems[i-1], systems[i]) {
+			continue
+		}
+		kind := "wormhole"
+		opts := routing.RouteOptions{Bridges: true, Now: time.Now()}
+		if e, ok := s.graph.Snapshot().Jump(systems[i-1], systems[i], opts); ok && e.Kind == routing.KindBridge {
+			kind = "jump bridge"
+		}
+		return systems[:i], kind
+	}
+	return systems, ""
+}
+
+// parseSystemIDs reads a comma-separated list of system IDs.
+func parseSystemIDs(raw string) ([]int, error) {
+	var ids []int
+	for _, field := range strings.Split(raw, ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// writeMessage answers a script on the page with a short JSON message to show the pilot.
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": message}); err != nil {
+		log.Printf("Failed to encode message: %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"wingspan-ops/internal/models"
+	"wingspan-ops/internal/routing"
+)
+
+// avoidListsPath is where each character's saved avoid list is kept.
+const avoidListsPath = "avoid_lists.json"
+
+// avoidStore holds every character's saved avoid list and keeps it on disk,
+// so lists survive logging out and aren't limited by the cookie size.
+type avoidStore struct {
+	mu    sync.Mutex
+	path  string
+	lists map[int]models.AvoidList
+}
+
+// load reads the saved avoid lists.
+func (a *avoidStore) load() error {
+	lists := make(map[int]models.AvoidList)
+	if _, err := loadJSON(a.path, &lists); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.lists = lists
+	a.mu.Unlock()
+	return nil
+}
+
+// get returns a character's saved avoid list, empty if it has none.
+func (a *avoidStore) get(charID int) models.AvoidList {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.lists[charID]
+}
+
+// put replaces a character's saved avoid list.
+func (a *avoidStore) put(charID int, list models.AvoidList) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lists[charID] = list
+	return saveJSON(a.path, a.lists)
+}
+
+// LoadAvoidLists reads the characters' saved avoid lists.
+func (s *Server) LoadAvoidLists() error {
+	if err := s.avoids.load(); err != nil {
+		return err
+	}
+	log.Printf("✅ Loaded avoid lists for %d characters.", len(s.avoids.lists))
+	return nil
+}
+
+// settingsHandler shows and saves the character's persistent avoid list.
+func (s *Server) settingsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		list := avoidListFromForm(r)
+
+		// Resolve the names now so typos are caught before they are saved.
+		if _, err := s.resolveAvoidance(r.Context(), list); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		charID, ok := s.characterID(r)
+		if !ok {
+			http.Redirect(w, r, "/login", http.StatusSeeOther)
+			return
+		}
+		if err := s.avoids.put(charID, list); err != nil {
+			log.Printf("ERROR: Failed to save avoid list: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, "/settings", http.StatusSeeOther)
+		return
+	}
+
+	data := models.FrontendData{
+		FeedbackURL:   s.feedbackURL,
+		CharacterName: s.getAuthenticatedUser(r),
+		Characters:    s.linkedCharacters(r),
+		SavedAvoid:    s.savedAvoidList(r),
+	}
+	ts, ok := s.templates["settings.html"]
+	if !ok {
+		http.Error(w, "Could not load settings.html template", http.StatusInternalServerError)
+		return
+	}
+	if err := ts.Execute(w, data); err != nil {
+		log.Printf("Template execution error: %v", err)
+	}
+}
+
+// savedAvoidList returns the logged-in character's persistent avoid list.
+func (s *Server) savedAvoidList(r *http.Request) models.AvoidList {
+	charID, ok := s.characterID(r)
+	if !ok {
+		return models.AvoidList{}
+	}
+	return s.avoids.get(charID)
+}
+
+// avoidListFromForm reads the avoid_* fields shared by the route and settings forms.
+func avoidListFromForm(r *http.Request) models.AvoidList {
+	return models.AvoidList{
+		Systems:        parseNameList(r.FormValue("avoid_systems")),
+		Constellations: parseNameList(r.FormValue("avoid_constellations")),
+		Regions:        parseNameList(r.FormValue("avoid_regions")),
+	}
+}
+
+// parseNameList splits a comma or newline separated list of names, dropping blanks and repeats.
+func parseNameList(raw string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, field := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == '\n' }) {
+		name := strings.TrimSpace(field)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// resolveAvoidance turns one or more avoid lists into the ID sets the router works with.
+func (s *Server) resolveAvoidance(ctx context.Context, lists ...models.AvoidList) (*routing.Avoidance, error) {
+	avoid := routing.NewAvoidance()
+	for _, list := range lists {
+		for _, name := range list.Systems {
+			id, err := s.esiClient.GetSystemID(ctx, name)
+			if err != nil {
+				return nil, fmt.Errorf("could not find system to avoid: %s", name)
+			}
+			avoid.Systems[id] = true
+		}
+		for _, name := range list.Constellations {
+			id, err := s.esiClient.GetConstellationID(ctx, name)
+			if err != nil {
+				return nil, fmt.Errorf("could not find constellation to avoid: %s", name)
+			}
+			avoid.Constellations[id] = true
+		}
+		for _, name := range list.Regions {
+			id, err := s.esiClient.GetRegionID(ctx, name)
+			if err != nil {
+				return nil, fmt.Errorf("could not find region to avoid: %s", name)
+			}
+			avoid.Regions[id] = true
+		}
+	}
+	return avoid, nil
+}
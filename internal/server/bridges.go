@@ -0,0 +1,125 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"wingspan-ops/internal/models"
+	"wingspan-ops/internal/routing"
+)
+
+// bridgesHandler shows the jump bridge network and lets admins replace it
+// with a freshly pasted list.
+func (s *Server) bridgesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		if !s.isAdmin(r) {
+			http.Error(w, "Only admins can edit the jump bridge list.", http.StatusForbidden)
+			return
+		}
+		lines, err := routing.ParseBridgeList(r.FormValue("bridges"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		bridges := make([]models.JumpBridge, 0, len(lines))
+		for _, line := range lines {
+			fromID, err := s.esiClient.GetSystemID(r.Context(), line.From)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Could not find system: %s", line.From), http.StatusBadRequest)
+				return
+			}
+			toID, err := s.esiClient.GetSystemID(r.Context(), line.To)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Could not find system: %s", line.To), http.StatusBadRequest)
+				return
+			}
+			bridges = append(bridges, models.JumpBridge{From: line.From, To: line.To, FromID: fromID, ToID: toID})
+		}
+		if err := s.saveBridges(bridges); err != nil {
+			log.Printf("ERROR: Failed to save jump bridges: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		s.publishBridges(bridges)
+		log.Printf("Jump bridge list replaced by %s: %d bridges.", s.getAuthenticatedUser(r), len(bridges))
+		http.Redirect(w, r, "/bridges", http.StatusSeeOther)
+		return
+	}
+
+	bridges, err := s.readBridges()
+	if err != nil {
+		log.Printf("WARN: Could not read jump bridges: %v", err)
+	}
+	data := models.FrontendData{
+		FeedbackURL:   s.feedbackURL,
+		CharacterName: s.getAuthenticatedUser(r),
+		Characters:    s.linkedCharacters(r),
+		Bridges:       bridges,
+		IsAdmin:       s.isAdmin(r),
+	}
+	ts, ok := s.templates["bridges.html"]
+	if !ok {
+		http.Error(w, "Could not load bridges.html template", http.StatusInternalServerError)
+		return
+	}
+	if err := ts.Execute(w, data); err != nil {
+		log.Printf("Template execution error: %v", err)
+	}
+}
+
+// LoadBridges adds the saved jump bridge network to the routing graph.
+// A missing file just means no bridges have been imported yet.
+func (s *Server) LoadBridges() error {
+	bridges, err := s.readBridges()
+	if err != nil {
+		return err
+	}
+	s.publishBridges(bridges)
+	log.Printf("✅ Loaded %d jump bridges.", len(bridges))
+	return nil
+}
+
+// readBridges reads the saved jump bridge list.
+func (s *Server) readBridges() ([]models.JumpBridge, error) {
+	var bridges []models.JumpBridge
+	if _, err := loadJSON(s.bridgesPath, &bridges); err != nil {
+		return nil, err
+	}
+	return bridges, nil
+}
+
+// saveBridges writes the jump bridge list.
+func (s *Server) saveBridges(bridges []models.JumpBridge) error {
+	return saveJSON(s.bridgesPath, bridges)
+}
+
+// publishBridges swaps the graph's jump bridges for bridges.
+func (s *Server) publishBridges(bridges []models.JumpBridge) {
+	links := make([]routing.WHLink, 0, len(bridges))
+	for _, b := range bridges {
+		links = append(links, routing.WHLink{
+			From:   b.FromID,
+			To:     b.ToID,
+			Cost:   1,
+			Kind:   routing.KindBridge,
+			Source: "Jump bridge",
+		})
+	}
+	s.graph.UpdateBridges(links)
+}
+
+// isAdmin reports whether the logged-in character may edit shared settings.
+func (s *Server) isAdmin(r *http.Request) bool {
+	id, ok := s.characterID(r)
+	return ok && s.admins[id]
+}
+
+// bridgeListText formats bridges back into the pasted list format.
+func bridgeListText(bridges []models.JumpBridge) string {
+	var b strings.Builder
+	for _, bridge := range bridges {
+		fmt.Fprintf(&b, "%s » %s\n", bridge.From, bridge.To)
+	}
+	return b.String()
+}
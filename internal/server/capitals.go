@@ -0,0 +1,106 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"wingspan-ops/internal/models"
+	"wingspan-ops/internal/routing"
+)
+
+// maxJumpRange is the longest jump range the planner accepts, in light-years.
+const maxJumpRange = 10
+
+// capitalsHandler plans jump drive routes for capitals between two systems.
+func (s *Server) capitalsHandler(w http.ResponseWriter, r *http.Request) {
+	data := models.FrontendData{
+		FeedbackURL:   s.feedbackURL,
+		CharacterName: s.getAuthenticatedUser(r),
+		Characters:    s.linkedCharacters(r),
+		WatchNotices:  s.watchNotices(r),
+		JumpRange:     routing.JumpShips[0].Range,
+		JumpMode:      string(routing.JumpFewest),
+		UseSavedAvoid: true,
+	}
+	if s.jumpSpace == nil {
+		data.CapitalError = "The capital planner is unavailable: no system coordinates have been loaded."
+	}
+
+	if r.Method == http.MethodPost && s.jumpSpace != nil {
+		data.StartSystem = r.FormValue("start_system")
+		data.EndSystem = r.FormValue("end_system")
+		data.JumpMode = string(routing.ParseJumpMode(r.FormValue("mode")))
+		data.UseSavedAvoid = r.FormValue("use_saved_avoid") == "on"
+		if v, err := strconv.ParseFloat(r.FormValue("jump_range"), 64); err == nil && v > 0 && v <= maxJumpRange {
+			data.JumpRange = v
+		}
+
+		startID, err := s.esiClient.GetSystemID(r.Context(), data.StartSystem)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Could not find start system: %s", data.StartSystem), http.StatusBadRequest)
+			return
+		}
+		endID, err := s.esiClient.GetSystemID(r.Context(), data.EndSystem)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Could not find end system: %s", data.EndSystem), http.StatusBadRequest)
+			return
+		}
+		var avoidLists []models.AvoidList
+		if data.UseSavedAvoid {
+			avoidLists = append(avoidLists, s.savedAvoidList(r))
+		}
+		avoid, err := s.resolveAvoidance(r.Context(), avoidLists...)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		systems, err := s.jumpSpace.PlanJumps(startID, endID, data.JumpRange, routing.JumpMode(data.JumpMode), func(id int) bool {
+			return s.graph.Avoids(avoid, id)
+		})
+		var notJumpable *routing.JumpableError
+		switch {
+		case errors.As(err, &notJumpable):
+			data.CapitalError = fmt.Sprintf("Jump drives can't be used in %s: capitals can't jump to or from high-sec, Pochven, Zarzakh or J-space.",
+				s.esiClient.GetSystemName(notJumpable.SystemID))
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case systems == nil:
+			data.CapitalError = fmt.Sprintf("%s is out of reach with a %.1f ly jump range.", data.EndSystem, data.JumpRange)
+		default:
+			data.CapitalJumps = s.capitalJumps(systems)
+			for _, jump := range data.CapitalJumps {
+				data.CapitalTotalLY += jump.LightYears
+			}
+		}
+	}
+
+	ts, ok := s.templates["capitals.html"]
+	if !ok {
+		http.Error(w, "Could not load capitals.html template", http.StatusInternalServerError)
+		return
+	}
+	if err := ts.Execute(w, data); err != nil {
+		log.Printf("Template execution error: %v", err)
+	}
+}
+
+// capitalJumps turns a jump plan into the rows shown on the capital planner.
+func (s *Server) capitalJumps(systems []int) []models.CapitalJump {
+	jumps := make([]models.CapitalJump, 0, len(systems))
+	for i, id := range systems {
+		jump := models.CapitalJump{SystemName: s.esiClient.GetSystemName(id)}
+		if sec, ok := s.esiClient.GetSecurityStatus(id); ok {
+			jump.SecurityStatus = sec
+			jump.SecurityClass = routing.SecurityClass(sec)
+		}
+		if i > 0 {
+			jump.LightYears, _ = s.jumpSpace.Distance(systems[i-1], id)
+		}
+		jumps = append(jumps, jump)
+	}
+	return jumps
+}
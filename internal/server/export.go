@@ -0,0 +1,206 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"wingspan-ops/internal/models"
+	"wingspan-ops/internal/routing"
+)
+
+// Route export formats, chosen with the format query parameter.
+const (
+	exportText    = "text"    // numbered jump list
+	exportChat    = "chat"    // in-game chat markup with showinfo system links
+	exportSummary = "summary" // one line for fleet comms
+	exportJSON    = "json"
+)
+
+// showInfoSolarSystem is the type ID the in-game showinfo link uses for solar systems.
+const showInfoSolarSystem = 5
+
+// routeExport is the JSON export of a route.
+type routeExport struct {
+	Start         string            `json:"start"`
+	End           string            `json:"end"`
+	Jumps         int               `json:"jumps"`
+	WormholeJumps int               `json:"wormhole_jumps"`
+	Risk          float64           `json:"risk"`
+	Steps         []routeExportStep `json:"steps"`
+}
+
+type routeExportStep struct {
+	SystemID      int     `json:"system_id"`
+	SystemName    string  `json:"system_name"`
+	Security      float64 `json:"security"`
+	JumpType      string  `json:"jump_type"`
+	Waypoint      bool    `json:"waypoint,omitempty"`
+	FromSignature string  `json:"from_signature,omitempty"`
+	ToSignature   string  `json:"to_signature,omitempty"`
+	WormholeType  string  `json:"wormhole_type,omitempty"`
+	Note          string  `json:"note,omitempty"`
+}
+
+// writeRouteExport writes the route in data in the requested format.
+func writeRouteExport(w http.ResponseWriter, format string, data models.FrontendData) {
+	if len(data.Path) == 0 {
+		http.Error(w, "No route could be found between the specified systems.", http.StatusNotFound)
+		return
+	}
+	switch format {
+	case exportText:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, routeText(data.Path))
+	case exportChat:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, routeChat(data.Path))
+	case exportSummary:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, routeSummary(data.Path))
+	case exportJSON:
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="route.json"`)
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(routeJSON(data)); err != nil {
+			log.Printf("WARN: Failed to write route export: %v", err)
+		}
+	default:
+		http.Error(w, fmt.Sprintf("Unknown export format %q.", format), http.StatusBadRequest)
+	}
+}
+
+// exportURL returns a GET URL that plans the same route as the current
+// request, for the export links on the results page.
+func exportURL(form url.Values) string {
+	q := url.Values{}
+	for key, values := range form {
+		if key != "format" {
+			q[key] = values
+		}
+	}
+	return "/short-circuit?" + q.Encode()
+}
+
+// selectRouteURL returns a GET URL for the results page with alternative n
+// selected.
+func selectRouteURL(form url.Values, n int) string {
+	q := url.Values{}
+	for key, values := range form {
+		if key != "format" && key != "route" {
+			q[key] = values
+		}
+	}
+	q.Set("route", strconv.Itoa(n))
+	return "/short-circuit?" + q.Encode()
+}
+
+// routeText lists every system on the route, one per line, with how it is reached.
+func routeText(path []models.PathStep) string {
+	var b strings.Builder
+	for i, step := range path {
+		fmt.Fprintf(&b, "%d. %s (%.1f)", i, step.SystemName, step.SecurityStatus)
+		switch {
+		case i == 0:
+			b.WriteString(" - start")
+		case step.FromSignature != "" || step.ToSignature != "":
+			fmt.Fprintf(&b, " - %s %s → %s", step.JumpType, orUnknown(step.FromSignature), orUnknown(step.ToSignature))
+		case step.JumpType != "":
+			fmt.Fprintf(&b, " - %s", step.JumpType)
+		}
+		if step.Waypoint {
+			b.WriteString(" [waypoint]")
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// routeChat joins the route's systems as showinfo links, which turn into
+// clickable system names when pasted into in-game chat.
+func routeChat(path []models.PathStep) string {
+	links := make([]string, len(path))
+	for i, step := range path {
+		links[i] = fmt.Sprintf("<url=showinfo:%d//%d>%s</url>", showInfoSolarSystem, step.SystemID, step.SystemName)
+	}
+	return strings.Join(links, " > ")
+}
+
+// routeSummary is a one-line route for fleet comms, such as
+// "Jita > Perimeter > ... > Amarr (12j, 2 WH)". Between the ends it keeps
+// only the systems either side of a wormhole or bridge and any waypoints,
+// since the gate legs in between are what the autopilot handles anyway.
+func routeSummary(path []models.PathStep) string {
+	keep := make([]bool, len(path))
+	keep[0], keep[len(path)-1] = true, true
+	holes := 0
+	for i, step := range path {
+		if i > 0 && isWormholeJump(step.JumpType) {
+			holes++
+		}
+		if i > 0 && step.JumpType != string(routing.KindStargate) {
+			keep[i-1], keep[i] = true, true
+		}
+		if step.Waypoint {
+			keep[i] = true
+		}
+	}
+
+	var names []string
+	for i, step := range path {
+		switch {
+		case keep[i]:
+			names = append(names, step.SystemName)
+		case keep[i-1]:
+			names = append(names, "...")
+		}
+	}
+	return fmt.Sprintf("%s (%dj, %d WH)", strings.Join(names, " > "), len(path)-1, holes)
+}
+
+// routeJSON builds the JSON export of the route in data.
+func routeJSON(data models.FrontendData) routeExport {
+	export := routeExport{
+		Start: data.Path[0].SystemName,
+		End:   data.Path[len(data.Path)-1].SystemName,
+		Jumps: len(data.Path) - 1,
+		Risk:  data.RouteRisk,
+	}
+	for i, step := range data.Path {
+		if i > 0 && isWormholeJump(step.JumpType) {
+			export.WormholeJumps++
+		}
+		export.Steps = append(export.Steps, routeExportStep{
+			SystemID:      step.SystemID,
+			SystemName:    step.SystemName,
+			Security:      step.SecurityStatus,
+			JumpType:      step.JumpType,
+			Waypoint:      step.Waypoint,
+			FromSignature: step.FromSignature,
+			ToSignature:   step.ToSignature,
+			WormholeType:  step.WormholeType,
+			Note:          step.Note,
+		})
+	}
+	return export
+}
+
+// isWormholeJump reports whether a step's jump type is a wormhole of any source.
+func isWormholeJump(jumpType string) bool {
+	switch routing.EdgeKind(jumpType) {
+	case routing.KindWormhole, routing.KindThera, routing.KindTurnur:
+		return true
+	}
+	return false
+}
+
+func orUnknown(sig string) string {
+	if sig == "" {
+		return "???"
+	}
+	return sig
+}
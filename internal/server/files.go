@@ -0,0 +1,44 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// loadJSON decodes the JSON file at path into v. A missing file is not an
+// error, since it only means nothing has been saved yet; found reports
+// whether there was one.
+func loadJSON(path string, v any) (found bool, err error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return true, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// saveJSON writes v to path as indented JSON.
+func saveJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0o644)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// into place, so a crash can't leave the file half written.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -3,17 +3,33 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 	"wingspan-ops/internal/esi"
 	"wingspan-ops/internal/fetcher"
 	"wingspan-ops/internal/models"
 	"wingspan-ops/internal/routing"
 )
 
+// maxAlternatives caps how many alternative routes a single request may ask for.
+const maxAlternatives = 5
+
+// characterID returns the logged-in character's ID from the session.
+func (s *Server) characterID(r *http.Request) (int, bool) {
+	session, err := s.sessionStore.Get(r, sessionName)
+	if err != nil {
+		return 0, false
+	}
+	id, ok := session.Values[sessionCharIDKey].(int)
+	return id, ok
+}
+
 // getAuthenticatedUser retrieves the character name from the session.
 func (s *Server) getAuthenticatedUser(r *http.Request) string {
 	session, err := s.sessionStore.Get(r, sessionName)
@@ -68,6 +84,8 @@ func (s *Server) homeHandler(w http.ResponseWriter, r *http.Request) {
 		Leaderboard:   leaderboard,
 		FeedbackURL:   s.feedbackURL,
 		CharacterName: s.getAuthenticatedUser(r),
+		Characters:    s.linkedCharacters(r),
+		WatchNotices:  s.watchNotices(r),
 	}
 
 	ts, ok := s.templates["index.html"]
@@ -86,64 +104,231 @@ func (s *Server) shortCircuitHandler(w http.ResponseWriter, r *http.Request) {
 	data := models.FrontendData{
 		FeedbackURL:   s.feedbackURL,
 		CharacterName: s.getAuthenticatedUser(r),
+		Characters:    s.linkedCharacters(r),
+		WatchNotices:  s.watchNotices(r),
 	}
 
-	if r.Method == http.MethodGet {
-		ts, ok := s.templates["short_circuit.html"]
-		if !ok {
-			http.Error(w, "Could not load template", http.StatusInternalServerError)
-			return
+	// A GET with a start system is a shared or exported route, planned the same as a POST.
+	if r.Method == http.MethodGet && r.FormValue("start_system") == "" {
+		data.UseSavedAvoid = true
+		data.UseBridges = true
+		if r.FormValue("use_location") == "on" {
+			s.prefillLocation(r, &data)
 		}
-		ts.Execute(w, data)
+		s.renderShortCircuit(w, data)
 		return
 	}
 
-	if r.Method == http.MethodPost {
+	if r.Method == http.MethodPost || r.Method == http.MethodGet {
 		startSystemName := r.FormValue("start_system")
 		endSystemName := r.FormValue("end_system")
+		profile := routing.ParseProfile(r.FormValue("profile"))
+		data.RouteProfile = string(profile)
+		ship := routing.ParseShipClass(r.FormValue("ship_class"))
+		data.ShipClass = string(ship)
+		data.StartSystem = startSystemName
+		data.EndSystem = endSystemName
+		data.Avoid = avoidListFromForm(r)
+		data.UseSavedAvoid = r.FormValue("use_saved_avoid") == "on"
+		data.UseBridges = r.FormValue("use_bridges") == "on"
+
+		data.IgnoreEOL = r.FormValue("ignore_eol") == "on"
+		data.IgnoreCritical = r.FormValue("ignore_critical") == "on"
+		data.MinHoursLeft, _ = strconv.Atoi(r.FormValue("min_hours_left"))
+		data.MaxAgeHours, _ = strconv.Atoi(r.FormValue("max_age_hours"))
+		whFilter := routing.WormholeFilter{
+			IgnoreEOL:      data.IgnoreEOL,
+			IgnoreCritical: data.IgnoreCritical,
+			MinHoursLeft:   data.MinHoursLeft,
+			MaxAge:         time.Duration(data.MaxAgeHours) * time.Hour,
+		}
+		data.Via = parseNameList(r.FormValue("via_systems"))
+		data.OptimiseOrder = r.FormValue("optimise_order") == "on"
+		data.Alternatives, _ = strconv.Atoi(r.FormValue("alternatives"))
+		if data.Alternatives < 1 || data.Alternatives > maxAlternatives {
+			data.Alternatives = 1
+		}
 
 		startID, err := s.esiClient.GetSystemID(context.Background(), startSystemName)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Could not find start system: %s", startSystemName), http.StatusBadRequest)
 			return
 		}
-		endID, err := s.esiClient.GetSystemID(context.Background(), endSystemName)
+		// A nearest-of-set preset replaces the end system; it is picked once the
+		// route options are known.
+		preset, byPreset := routing.ParsePreset(r.FormValue("nearest"))
+		data.Nearest = string(preset)
+		var endID int
+		if !byPreset {
+			endID, err = s.esiClient.GetSystemID(context.Background(), endSystemName)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Could not find end system: %s", endSystemName), http.StatusBadRequest)
+				return
+			}
+		}
+		waypoints := []int{startID}
+		for _, name := range data.Via {
+			id, err := s.esiClient.GetSystemID(context.Background(), name)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Could not find waypoint system: %s", name), http.StatusBadRequest)
+				return
+			}
+			waypoints = append(waypoints, id)
+		}
+		if !byPreset {
+			waypoints = append(waypoints, endID)
+		}
+
+		avoidLists := []models.AvoidList{data.Avoid}
+		if data.UseSavedAvoid {
+			avoidLists = append(avoidLists, s.savedAvoidList(r))
+		}
+		avoid, err := s.resolveAvoidance(r.Context(), avoidLists...)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Could not find end system: %s", endSystemName), http.StatusBadRequest)
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		killMap := make(map[int]esi.EsiSystemKills)
-		killData, err := os.ReadFile("kills.json")
-		if err != nil {
-			log.Printf("WARN: Could not read kills.json file for routing: %v", err)
-		} else {
-			var kills []esi.EsiSystemKills
-			if err := json.Unmarshal(killData, &kills); err == nil {
-				for _, k := range kills {
-					killMap[k.SystemID] = k
-				}
+		killMap := readKillMap()
+		risk := s.riskScores(killMap)
+
+		snap := s.graph.Snapshot()
+		opts := routing.RouteOptions{
+			Profile:   profile,
+			Security:  s.esiClient.GetSecurityStatus,
+			Risk:      func(id int) float64 { return risk[id] },
+			Avoid:     avoid,
+			Ship:      ship,
+			Bridges:   data.UseBridges,
+			Wormholes: whFilter,
+			Now:       time.Now(),
+			Algorithm: routing.ParseAlgorithm(r.FormValue("algorithm")),
+		}
+		key := routeCacheKey(snap.Overlay().Version(), waypoints, preset, data.OptimiseOrder, data.Alternatives, opts)
+		plan, ok := s.routes.get(key, opts.Now)
+		if !ok {
+			plan, err = s.planRoute(snap, waypoints, preset, byPreset, data.OptimiseOrder, data.Alternatives, opts)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
 			}
+			s.routes.put(key, plan, opts.Now)
 		}
 
-		wingspanResponse, _ := fetcher.FetchWingspanData(s.wingspanURL)
-		theraResponse, _ := fetcher.FetchTheraData()
-		whLinks := processConnectionsToWHLinks(wingspanResponse, theraResponse, s.esiClient)
+		if plan.Nearest != 0 {
+			data.NearestSystem = s.esiClient.GetSystemName(plan.Nearest)
+		}
+		if data.OptimiseOrder && len(plan.Waypoints) >= 2 {
+			data.Via = data.Via[:0]
+			for _, id := range plan.Waypoints[1 : len(plan.Waypoints)-1] {
+				data.Via = append(data.Via, s.esiClient.GetSystemName(id))
+			}
+		}
+		data.NoRoute = plan.NoRoute
+		data.AvoidNotice = plan.AvoidNotice
+		data.OrderNotice = plan.OrderNotice
+		if plan.Legs != nil {
+			steps := &stepBuilder{
+				graph:     snap,
+				opts:      opts,
+				esiClient: s.esiClient,
+				killMap:   killMap,
+				risk:      risk,
+				now:       opts.Now,
+			}
+			data.Path = steps.stitch(plan.Legs)
+			data.RouteRisk, data.Hotspots = summariseRisk(data.Path)
+			for n, systems := range plan.Alternatives {
+				route := steps.summarise(systems)
+				route.SelectURL = selectRouteURL(r.Form, n+1)
+				data.Routes = append(data.Routes, route)
+			}
+			// The copy, export, autopilot and watch controls act on one
+			// route: the cheapest unless the pilot picked another.
+			if len(data.Routes) > 1 {
+				data.SelectedRoute = 1
+				if n, err := strconv.Atoi(r.FormValue("route")); err == nil && n > 1 && n <= len(data.Routes) {
+					data.SelectedRoute = n
+					data.Path = data.Routes[n-1].Path
+					data.RouteRisk, data.Hotspots = summariseRisk(data.Path)
+				}
+			}
+		}
+		if format := r.FormValue("format"); format != "" {
+			writeRouteExport(w, format, data)
+			return
+		}
+		if len(data.Path) > 0 {
+			data.RouteText = routeText(data.Path)
+			data.RouteChat = routeChat(data.Path)
+			data.RouteSummary = routeSummary(data.Path)
+			data.ExportURL = exportURL(r.Form)
+		}
+		s.renderShortCircuit(w, data)
+	}
+}
 
-		requestGraph := s.graph.Clone()
-		requestGraph.UpdateWormholes(whLinks)
+// planRoute does the routing behind a Short Circuit query: it resolves a
+// nearest-of-set preset, reorders the waypoints if asked, plans each leg and
+// finds any alternatives. A route that can't be completed is reported in the
+// plan rather than as an error, so it can be cached like any other.
+func (s *Server) planRoute(snap *routing.Snapshot, waypoints []int, preset routing.Preset, byPreset, optimise bool, alternatives int, opts routing.RouteOptions) (*routePlan, error) {
+	plan := &routePlan{}
+	waypoints = append([]int(nil), waypoints...)
+	if byPreset {
+		from := waypoints[len(waypoints)-1]
+		endID, path := snap.Nearest(from, snap.PresetTargets(preset, opts), opts)
+		if path == nil {
+			plan.NoRoute = true
+			return plan, nil
+		}
+		waypoints = append(waypoints, endID)
+		plan.Nearest = endID
+	}
+	if optimise {
+		ordered, err := snap.OptimiseOrder(waypoints, opts)
+		var noRoute *routing.NoRouteError
+		switch {
+		case err == nil:
+			waypoints = ordered
+		case errors.As(err, &noRoute):
+			plan.OrderNotice = fmt.Sprintf("Couldn't optimise the waypoint order: there is no route from %s to %s. The waypoints are visited in the order given.",
+				s.esiClient.GetSystemName(noRoute.From), s.esiClient.GetSystemName(noRoute.To))
+		default:
+			plan.OrderNotice = fmt.Sprintf("Couldn't optimise the waypoint order: %v. The waypoints are visited in the order given.", err)
+		}
+	}
+	plan.Waypoints = waypoints
+
+	legs, err := snap.PlanWaypoints(waypoints, opts)
+	var noRoute *routing.NoRouteError
+	if errors.As(err, &noRoute) {
+		plan.NoRoute = true
+		if !opts.Avoid.Empty() {
+			plan.AvoidNotice = avoidanceNotice(snap, noRoute.From, noRoute.To, opts, s.esiClient)
+		}
+		return plan, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	plan.Legs = legs
 
-		_, prev := requestGraph.ShortestPath(startID, endID)
-		path := reconstructPath(prev, startID, endID, s.esiClient, killMap)
+	// Alternatives only make sense for a plain A to B route.
+	if len(waypoints) == 2 && alternatives > 1 {
+		plan.Alternatives = snap.KShortestPaths(waypoints[0], waypoints[1], alternatives, opts)
+	}
+	return plan, nil
+}
 
-		data.Path = path
-		ts, ok := s.templates["short_circuit.html"]
-		if !ok {
-			http.Error(w, "Could not load template", http.StatusInternalServerError)
-			return
-		}
-		ts.Execute(w, data)
+// renderShortCircuit renders the route planner page.
+func (s *Server) renderShortCircuit(w http.ResponseWriter, data models.FrontendData) {
+	ts, ok := s.templates["short_circuit.html"]
+	if !ok {
+		http.Error(w, "Could not load template", http.StatusInternalServerError)
+		return
 	}
+	ts.Execute(w, data)
 }
 
 // lookupHandler handles the character lookup page and form submissions.
@@ -152,6 +337,7 @@ func (s *Server) lookupHandler(w http.ResponseWriter, r *http.Request) {
 		data := models.FrontendData{
 			FeedbackURL:   s.feedbackURL,
 			CharacterName: s.getAuthenticatedUser(r),
+			Characters:    s.linkedCharacters(r),
 		}
 		ts, ok := s.templates["lookup.html"]
 		if !ok {
@@ -187,6 +373,7 @@ func (s *Server) aboutHandler(w http.ResponseWriter, r *http.Request) {
 	data := models.FrontendData{
 		FeedbackURL:   s.feedbackURL,
 		CharacterName: s.getAuthenticatedUser(r),
+		Characters:    s.linkedCharacters(r),
 	}
 
 	ts, ok := s.templates["about.html"]
@@ -213,6 +400,25 @@ func (s *Server) loginPageHandler(w http.ResponseWriter, r *http.Request) {
 
 // --- Processing functions ---
 
+// avoidanceNotice explains a failed search when the avoid list is the reason
+// no route was found, naming the avoided systems the unrestricted route uses.
+func avoidanceNotice(g *routing.Snapshot, start, end int, opts routing.RouteOptions, esiClient *esi.ESIClient) string {
+	avoid := opts.Avoid
+	opts.Avoid = nil
+	systems := g.Path(start, end, opts)
+	if systems == nil {
+		return ""
+	}
+
+	var blocked []string
+	for _, id := range systems[1 : len(systems)-1] {
+		if g.Avoids(avoid, id) {
+			blocked = append(blocked, esiClient.GetSystemName(id))
+		}
+	}
+	return fmt.Sprintf("A route exists, but only through systems on your avoid list: %s.", strings.Join(blocked, ", "))
+}
+
 func processConnectionsToWHLinks(wingspanResponse *models.WingspanAPIResponse, theraResponse []models.TheraConnection, esiClient *esi.ESIClient) []routing.WHLink {
 	var links []routing.WHLink
 
@@ -230,68 +436,57 @@ func processConnectionsToWHLinks(wingspanResponse *models.WingspanAPIResponse, t
 				continue
 			}
 
-			links = append(links, routing.WHLink{From: fromID, To: toID, Cost: 1})
+			var whType string
+			if wh.Type != nil {
+				whType = *wh.Type
+			}
+			link := routing.WHLink{
+				From:          fromID,
+				To:            toID,
+				Cost:          1,
+				Kind:          routing.KindWormhole,
+				Source:        "Wingspan",
+				Scout:         sigInitial.ModifiedByName,
+				UpdatedAt:     parseTripwireTime(sigInitial.ModifiedTime),
+				Type:          whType,
+				MaxShipSize:   routing.SizeForType(whType),
+				Mass:          wh.Mass,
+				Life:          wh.Life,
+				FromSignature: signatureLabel(sigInitial.SignatureID),
+				ToSignature:   signatureLabel(sigSecondary.SignatureID),
+				CreatedAt:     parseTripwireTime(sigInitial.LifeTime),
+				ExpiresAt:     parseTripwireTime(sigInitial.LifeLeft),
+			}
+			links = append(links, link)
 		}
 	}
 
 	if theraResponse != nil {
 		for _, tc := range theraResponse {
 			if tc.OutSystemID >= 30000000 && tc.InSystemID >= 30000000 {
-				links = append(links, routing.WHLink{From: tc.OutSystemID, To: tc.InSystemID, Cost: 1})
+				kind := routing.KindThera
+				if tc.OutSystemID == routing.TurnurSystemID {
+					kind = routing.KindTurnur
+				}
+				link := routing.WHLink{
+					From:          tc.OutSystemID,
+					To:            tc.InSystemID,
+					Cost:          1,
+					Kind:          kind,
+					Source:        "EVE-Scout",
+					Scout:         tc.UpdatedByName,
+					UpdatedAt:     tc.UpdatedAt,
+					Type:          tc.WhType,
+					MaxShipSize:   routing.ParseMaxShipSize(tc.MaxShipSize),
+					FromSignature: formatSignatureID(strings.ToUpper(tc.OutSignature)),
+					ToSignature:   formatSignatureID(strings.ToUpper(tc.InSignature)),
+					CreatedAt:     tc.CreatedAt,
+					ExpiresAt:     tc.ExpiresAt,
+				}
+				links = append(links, link)
 			}
 		}
 	}
 
 	return links
 }
-
-func reconstructPath(prev map[int]int, start, end int, esiClient *esi.ESIClient, killMap map[int]esi.EsiSystemKills) []models.PathStep {
-	var path []models.PathStep
-	current := end
-
-	if _, ok := prev[current]; !ok && current != start {
-		return nil // No path found
-	}
-
-	for {
-		sysInfo, err := esiClient.GetSystemDetails(current)
-		kills := killMap[current]
-		var step models.PathStep
-		if err != nil {
-			step = models.PathStep{
-				SystemName: esiClient.GetSystemName(current),
-				ShipKills:  kills.ShipKills,
-				NpcKills:   kills.NpcKills,
-			}
-		} else {
-			var secClass string
-			if sysInfo.SecurityStatus >= 0.5 {
-				secClass = "high-sec"
-			} else if sysInfo.SecurityStatus > 0.0 {
-				secClass = "low-sec"
-			} else {
-				secClass = "null-sec"
-			}
-			step = models.PathStep{
-				SystemName:     sysInfo.Name,
-				SecurityStatus: sysInfo.SecurityStatus,
-				SecurityClass:  secClass,
-				ShipKills:      kills.ShipKills,
-				NpcKills:       kills.NpcKills,
-			}
-		}
-		path = append(path, step)
-
-		if current == start {
-			break
-		}
-		current = prev[current]
-	}
-
-	// Reverse the path
-	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
-		path[i], path[j] = path[j], path[i]
-	}
-
-	return path
-}
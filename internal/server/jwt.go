@@ -0,0 +1,217 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// eveJWKSURL publishes the keys EVE SSO signs access tokens with.
+	eveJWKSURL = "https://login.eveonline.com/oauth/jwks"
+	// jwksTTL is how long fetched keys are trusted before being fetched again.
+	jwksTTL = 24 * time.Hour
+	// jwksMinRefresh stops a flood of tokens with unknown key IDs from
+	// hammering the JWKS endpoint.
+	jwksMinRefresh = time.Minute
+	// jwtLeeway allows for clock skew between us and SSO when checking expiry.
+	jwtLeeway = 30 * time.Second
+
+	ssoAudience        = "EVE Online"
+	ssoCharacterPrefix = "CHARACTER:EVE:"
+)
+
+// ssoIssuers are the issuer values EVE SSO is documented to use.
+var ssoIssuers = []string{"login.eveonline.com", "https://login.eveonline.com"}
+
+// jwksCache holds EVE SSO's RSA signing keys by key ID, fetching them again
+// once they are stale or a token names a key it hasn't seen.
+type jwksCache struct {
+	url    string
+	client *http.Client
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time
+}
+
+func newJWKSCache(url string) *jwksCache {
+	return &jwksCache{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// key returns the public key with the given ID. If the keys can't be
+// refetched, the ones already held are used until a fetch succeeds.
+func (c *jwksCache) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key, ok := c.keys[kid]
+	age := time.Since(c.fetched)
+	if ok && age < jwksTTL {
+		return key, nil
+	}
+	if c.keys == nil || age >= jwksMinRefresh {
+		keys, err := c.fetch(ctx)
+		if err != nil && !ok {
+			return nil, err
+		}
+		if err == nil {
+			c.keys, c.fetched = keys, time.Now()
+		}
+	}
+	if key, ok := c.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+type jwks struct {
+	Keys []struct {
+		Kid string `json:"kid"`
+		Kty string `json:"kty"`
+		Alg string `json:"alg"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// fetch downloads the key set and keeps its RS256 keys.
+func (c *jwksCache) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS endpoint returned non-200 status: %s", resp.Status)
+	}
+	var set jwks
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Alg != "" && k.Alg != "RS256") {
+			continue
+		}
+		n, err1 := base64.RawURLEncoding.DecodeString(k.N)
+		e, err2 := base64.RawURLEncoding.DecodeString(k.E)
+		if err1 != nil || err2 != nil || len(e) > 4 {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS holds no RS256 keys")
+	}
+	return keys, nil
+}
+
+// stringList decodes a JWT claim that may be a single string or an array of them.
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(data, []byte(`"`)) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*l = stringList{s}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
+
+type ssoClaims struct {
+	Subject  string     `json:"sub"`
+	Name     string     `json:"name"`
+	Issuer   string     `json:"iss"`
+	Audience stringList `json:"aud"`
+	Expiry   int64      `json:"exp"`
+	Scopes   stringList `json:"scp"`
+}
+
+// verifyAccessToken checks an EVE SSO v2 access token's RS256 signature
+// against the SSO key set, then its issuer, audience and expiry, and returns
+// the character it was issued to.
+func (s *Server) verifyAccessToken(ctx context.Context, raw string) (*EveVerifyResponse, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("access token is not a JWT")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("bad JWT header: %w", err)
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("unexpected JWT algorithm %q", header.Alg)
+	}
+	key, err := s.jwks.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("bad JWT signature encoding: %w", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+		return nil, errors.New("JWT signature does not match")
+	}
+
+	var claims ssoClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("bad JWT claims: %w", err)
+	}
+	if !slices.Contains(ssoIssuers, claims.Issuer) {
+		return nil, fmt.Errorf("unexpected JWT issuer %q", claims.Issuer)
+	}
+	if !slices.Contains(claims.Audience, ssoAudience) || !slices.Contains(claims.Audience, s.oauthConfig.ClientID) {
+		return nil, errors.New("JWT was not issued for this application")
+	}
+	if time.Now().Add(-jwtLeeway).Unix() >= claims.Expiry {
+		return nil, errors.New("JWT has expired")
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(claims.Subject, ssoCharacterPrefix))
+	if err != nil || !strings.HasPrefix(claims.Subject, ssoCharacterPrefix) {
+		return nil, fmt.Errorf("unexpected JWT subject %q", claims.Subject)
+	}
+
+	return &EveVerifyResponse{
+		CharacterID:   id,
+		CharacterName: claims.Name,
+		Scopes:        claims.Scopes,
+	}, nil
+}
+
+// decodeSegment decodes one base64url part of a JWT as JSON.
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const testClientID = "test-client"
+
+// jwtFixture serves an RSA key as a JWKS and signs tokens with it.
+type jwtFixture struct {
+	key     *rsa.PrivateKey
+	fetches atomic.Int32
+	srv     *Server
+}
+
+func newJWTFixture(t *testing.T) *jwtFixture {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &jwtFixture{key: key}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.fetches.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kid": "JWT-Signature-Key",
+			"kty": "RSA",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	t.Cleanup(ts.Close)
+	f.srv = &Server{
+		jwks:        newJWKSCache(ts.URL),
+		oauthConfig: &oauth2.Config{ClientID: testClientID},
+	}
+	return f
+}
+
+// validClaims returns the claims of a token EVE SSO would issue to us.
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub":  "CHARACTER:EVE:90000001",
+		"name": "Test Pilot",
+		"iss":  "https://login.eveonline.com",
+		"aud":  []string{testClientID, ssoAudience},
+		"exp":  time.Now().Add(20 * time.Minute).Unix(),
+		"scp":  []string{"esi-location.read_location.v1"},
+	}
+}
+
+// sign builds a JWT from header and claims, signed with key.
+func sign(t *testing.T, key *rsa.PrivateKey, header, claims map[string]any) string {
+	t.Helper()
+	segment := func(v any) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := segment(header) + "." + segment(claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestVerifyAccessToken(t *testing.T) {
+	f := newJWTFixture(t)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := map[string]any{"alg": "RS256", "kid": "JWT-Signature-Key", "typ": "JWT"}
+	with := func(key string, value any) map[string]any {
+		c := validClaims()
+		c[key] = value
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{"valid", sign(t, f.key, header, validClaims()), ""},
+		{"bad signature", sign(t, other, header, validClaims()), "signature does not match"},
+		{"wrong alg", sign(t, f.key, map[string]any{"alg": "HS256", "kid": "JWT-Signature-Key"}, validClaims()), "unexpected JWT algorithm"},
+		{"wrong issuer", sign(t, f.key, header, with("iss", "https://evil.example")), "unexpected JWT issuer"},
+		{"audience without client ID", sign(t, f.key, header, with("aud", ssoAudience)), "not issued for this application"},
+		{"expired", sign(t, f.key, header, with("exp", time.Now().Add(-time.Hour).Unix())), "expired"},
+		{"malformed subject", sign(t, f.key, header, with("sub", "CHARACTER:EVE:abc")), "unexpected JWT subject"},
+		{"subject without prefix", sign(t, f.key, header, with("sub", "90000001")), "unexpected JWT subject"},
+		{"not a JWT", "opaque-token", "not a JWT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := f.srv.verifyAccessToken(context.Background(), tt.token)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got.CharacterID != 90000001 || got.CharacterName != "Test Pilot" || len(got.Scopes) != 1 {
+					t.Errorf("got %+v", got)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyAccessTokenUnknownKeyRefetchLimit(t *testing.T) {
+	f := newJWTFixture(t)
+	ctx := context.Background()
+	unknown := sign(t, f.key, map[string]any{"alg": "RS256", "kid": "rotated"}, validClaims())
+
+	if _, err := f.srv.verifyAccessToken(ctx, unknown); err == nil || !strings.Contains(err.Error(), "unknown signing key") {
+		t.Fatalf("error = %v, want unknown signing key", err)
+	}
+	if n := f.fetches.Load(); n != 1 {
+		t.Fatalf("JWKS fetched %d times on first use, want 1", n)
+	}
+
+	// Within jwksMinRefresh of the last fetch, unknown keys don't refetch.
+	for range 5 {
+		f.srv.verifyAccessToken(ctx, unknown)
+	}
+	if n := f.fetches.Load(); n != 1 {
+		t.Fatalf("JWKS fetched %d times within jwksMinRefresh, want 1", n)
+	}
+
+	// Once jwksMinRefresh has passed, a flood of them refetches once.
+	f.srv.jwks.mu.Lock()
+	f.srv.jwks.fetched = time.Now().Add(-jwksMinRefresh)
+	f.srv.jwks.mu.Unlock()
+	for range 5 {
+		f.srv.verifyAccessToken(ctx, unknown)
+	}
+	if n := f.fetches.Load(); n != 2 {
+		t.Fatalf("JWKS fetched %d times after jwksMinRefresh, want 2", n)
+	}
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"wingspan-ops/internal/models"
+	"wingspan-ops/internal/routing"
+)
+
+// prefillLocation fills the route form's start system and ship class from
+// where the character is in game and what they are flying.
+func (s *Server) prefillLocation(r *http.Request, data *models.FrontendData) {
+	charID, ok := s.characterID(r)
+	if !ok {
+		data.LocationError = "Log in to use your current location."
+		return
+	}
+	token, err := s.tokens.accessToken(r.Context(), charID, scopeReadLocation)
+	if errors.Is(err, errNoToken) || errors.Is(err, errMissingScope) {
+		data.LocationError = "Log in again and allow Short Circuit to read your location."
+		return
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to get access token for char ID %d: %v", charID, err)
+		data.LocationError = "Could not reach EVE SSO to read your location."
+		return
+	}
+	loc, err := s.esiClient.GetCharacterLocation(r.Context(), token, charID)
+	if err != nil {
+		log.Printf("ERROR: Failed to get location for char ID %d: %v", charID, err)
+		data.LocationError = "Could not read your location from ESI."
+		return
+	}
+
+	data.StartSystem = s.esiClient.GetSystemName(loc.SolarSystemID)
+	if groupID, err := s.esiClient.GetTypeGroupID(r.Context(), loc.ShipTypeID); err != nil {
+		log.Printf("WARN: Failed to look up ship type %d: %v", loc.ShipTypeID, err)
+	} else {
+		data.ShipClass = string(routing.ShipClassForGroup(groupID))
+	}
+}
@@ -19,6 +19,26 @@ func formatSignatureID(sigID string) string {
 	return sigID
 }
 
+// signatureLabel formats a Tripwire signature ID for display, or returns "" if it hasn't been scanned.
+func signatureLabel(sigID *string) string {
+	if sigID == nil || *sigID == "" {
+		return ""
+	}
+	return formatSignatureID(strings.ToUpper(*sigID))
+}
+
+// tripwireTimeLayout is the format Tripwire uses for signature timestamps, in UTC.
+const tripwireTimeLayout = "2006-01-02 15:04:05"
+
+// parseTripwireTime parses a Tripwire timestamp, returning the zero time if it can't.
+func parseTripwireTime(s string) time.Time {
+	t, err := time.Parse(tripwireTimeLayout, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
 func processAPIResponse(response *models.WingspanAPIResponse, esiClient *esi.ESIClient, killMap map[int]esi.EsiSystemKills) ([]models.ConnectionInfo, []models.LeaderboardEntry) {
 	var connections []models.ConnectionInfo
 	scanCounts := make(map[string]int)
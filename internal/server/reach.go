@@ -0,0 +1,100 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"wingspan-ops/internal/models"
+	"wingspan-ops/internal/routing"
+)
+
+// Reach page jump limits. Much past ten jumps the list covers most of a region.
+const (
+	defaultReachJumps = 5
+	maxReachJumps     = 10
+)
+
+// reachOrder is the order security classes are listed in within each distance.
+var reachOrder = []string{"high-sec", "low-sec", "null-sec", "j-space"}
+
+// reachHandler lists every system within a number of jumps of a starting
+// system, counting live wormhole connections as single jumps.
+func (s *Server) reachHandler(w http.ResponseWriter, r *http.Request) {
+	data := models.FrontendData{
+		FeedbackURL:   s.feedbackURL,
+		CharacterName: s.getAuthenticatedUser(r),
+		Characters:    s.linkedCharacters(r),
+		WatchNotices:  s.watchNotices(r),
+		ReachFrom:     r.FormValue("from"),
+		ShipClass:     r.FormValue("ship_class"),
+		ReachJumps:    defaultReachJumps,
+	}
+	if n, err := strconv.Atoi(r.FormValue("jumps")); err == nil && n >= 1 && n <= maxReachJumps {
+		data.ReachJumps = n
+	}
+
+	ts, ok := s.templates["reach.html"]
+	if !ok {
+		http.Error(w, "Could not load reach.html template", http.StatusInternalServerError)
+		return
+	}
+
+	if data.ReachFrom != "" {
+		fromID, err := s.esiClient.GetSystemID(r.Context(), data.ReachFrom)
+		if err != nil {
+			http.Error(w, "Could not find system: "+data.ReachFrom, http.StatusBadRequest)
+			return
+		}
+		opts := routing.RouteOptions{
+			Ship: routing.ParseShipClass(data.ShipClass),
+			Now:  time.Now(),
+		}
+		data.Reach = s.groupReach(s.graph.Snapshot().Reachable(fromID, data.ReachJumps, opts))
+	}
+
+	if err := ts.Execute(w, data); err != nil {
+		log.Printf("Template execution error: %v", err)
+	}
+}
+
+// groupReach sorts reachable systems into one group per jump count and,
+// within each, one list per security class.
+func (s *Server) groupReach(found []routing.Reach) []models.ReachGroup {
+	var groups []models.ReachGroup
+	byClass := make(map[string][]models.ReachSystem)
+	flush := func(jumps int) {
+		group := models.ReachGroup{Jumps: jumps}
+		for _, class := range reachOrder {
+			if systems := byClass[class]; len(systems) > 0 {
+				slices.SortFunc(systems, func(a, b models.ReachSystem) int { return strings.Compare(a.Name, b.Name) })
+				group.Classes = append(group.Classes, models.ReachClass{SecurityClass: class, Systems: systems})
+			}
+		}
+		groups = append(groups, group)
+		byClass = make(map[string][]models.ReachSystem)
+	}
+
+	for i, r := range found {
+		if i > 0 && r.Jumps != found[i-1].Jumps {
+			flush(found[i-1].Jumps)
+		}
+		system := models.ReachSystem{
+			Name:  s.esiClient.GetSystemName(r.SystemID),
+			Entry: string(r.Entry),
+		}
+		_, system.TradeHub = routing.TradeHubs[r.SystemID]
+		system.SecurityStatus, _ = s.esiClient.GetSecurityStatus(r.SystemID)
+		class := routing.SecurityClass(system.SecurityStatus)
+		if routing.IsJSpace(r.SystemID) {
+			class = "j-space"
+		}
+		byClass[class] = append(byClass[class], system)
+	}
+	if len(found) > 0 {
+		flush(found[len(found)-1].Jumps)
+	}
+	return groups
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"log"
+	"sync"
+	"time"
+	"wingspan-ops/internal/fetcher"
+	"wingspan-ops/internal/models"
+)
+
+// wormholeRefreshInterval is how often both wormhole APIs are polled for the routing overlay.
+const wormholeRefreshInterval = 1 * time.Minute
+
+// StartWormholeRefresher keeps the routing graph's wormhole overlay up to date.
+// Route requests read whatever overlay was last published, so they never wait on the APIs.
+// Watched routes are re-planned after each refresh.
+func (s *Server) StartWormholeRefresher(wg *sync.WaitGroup) {
+	defer wg.Done()
+	log.Println("[REFRESHER] Starting background wormhole refresher...")
+	ticker := time.NewTicker(wormholeRefreshInterval)
+	defer ticker.Stop()
+
+	// The last good response from each source, so one API being down
+	// doesn't wipe its connections from the overlay.
+	var wingspanResponse *models.WingspanAPIResponse
+	var theraResponse []models.TheraConnection
+
+	for {
+		if resp, err := fetcher.FetchWingspanData(s.wingspanURL); err != nil {
+			log.Printf("[REFRESHER] WARN: Failed to fetch from Wingspan API: %v", err)
+		} else {
+			wingspanResponse = resp
+		}
+		if resp, err := fetcher.FetchEveScoutData(); err != nil {
+			log.Printf("[REFRESHER] WARN: Failed to fetch from EVE-Scout API: %v", err)
+		} else {
+			theraResponse = resp
+		}
+
+		links := processConnectionsToWHLinks(wingspanResponse, theraResponse, s.esiClient)
+		if s.graph.UpdateWormholes(links) {
+			log.Printf("[REFRESHER] Published %d wormhole connections (version %s).", len(links), s.graph.Snapshot().Overlay().Version())
+		}
+		s.checkWatches()
+
+		<-ticker.C
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"wingspan-ops/internal/esi"
+	"wingspan-ops/internal/models"
+	"wingspan-ops/internal/routing"
+)
+
+// readKillMap loads the latest hourly kill counts written by the kill updater.
+func readKillMap() map[int]esi.EsiSystemKills {
+	killMap := make(map[int]esi.EsiSystemKills)
+	killData, err := os.ReadFile("kills.json")
+	if err != nil {
+		log.Printf("WARN: Could not read kills.json file for routing: %v", err)
+		return killMap
+	}
+	var kills []esi.EsiSystemKills
+	if err := json.Unmarshal(killData, &kills); err == nil {
+		for _, k := range kills {
+			killMap[k.SystemID] = k
+		}
+	}
+	return killMap
+}
+
+// riskScores rates every system with recent kills against its baseline.
+// Systems missing from the result have a risk of zero.
+func (s *Server) riskScores(killMap map[int]esi.EsiSystemKills) map[int]float64 {
+	var baseline models.KillBaseline
+	if data, err := os.ReadFile(s.baselinePath); err != nil {
+		log.Printf("WARN: Could not read %s, scoring risk on raw kills: %v", s.baselinePath, err)
+	} else if err := json.Unmarshal(data, &baseline); err != nil {
+		log.Printf("WARN: Could not decode %s: %v", s.baselinePath, err)
+	}
+
+	scores := make(map[int]float64)
+	for id, k := range killMap {
+		recent := k.ShipKills + k.PodKills
+		usual := baseline.Systems[id]
+		if baseline.Systems == nil {
+			usual = float64(recent) // no history yet: score the raw kill count
+		}
+		if risk := routing.RiskScore(recent, usual); risk > 0 {
+			scores[id] = risk
+		}
+	}
+	return scores
+}
+
+// summariseRisk totals the risk along a route and names its hotspots.
+func summariseRisk(path []models.PathStep) (float64, []string) {
+	var total float64
+	var hotspots []string
+	for _, step := range path {
+		total += step.Risk
+		if step.Hotspot {
+			hotspots = append(hotspots, step.SystemName)
+		}
+	}
+	return total, hotspots
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"wingspan-ops/internal/routing"
+)
+
+const (
+	// routeCacheSize is how many planned routes are kept.
+	routeCacheSize = 512
+	// routeCacheTTL bounds how long a route is reused. Wormhole life filters
+	// and kill counts move on with the clock even when the overlay doesn't,
+	// so entries are dropped once they are older than a refresh.
+	routeCacheTTL = wormholeRefreshInterval
+)
+
+// routePlan is the routing work behind one Short Circuit query: everything
+// except the per-step details, which are rebuilt from fresh kill data.
+type routePlan struct {
+	Waypoints    []int // final waypoint order, nearest-of-set match included
+	Nearest      int   // system picked by a nearest-of-set preset, 0 if none
+	Legs         [][]int
+	NoRoute      bool
+	AvoidNotice  string
+	OrderNotice  string // why the waypoints couldn't be reordered
+	Alternatives [][]int
+}
+
+type routeCacheEntry struct {
+	key     string
+	plan    *routePlan
+	created time.Time
+}
+
+// routeCache is an LRU cache of planned routes. Keys include the overlay
+// version, so a changed wormhole or bridge set never serves a stale route.
+type routeCache struct {
+	mu     sync.Mutex
+	size   int
+	order  *list.List // most recently used at the front
+	items  map[string]*list.Element
+	hits   uint64
+	misses uint64
+}
+
+func newRouteCache(size int) *routeCache {
+	return &routeCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+// get returns the plan cached under key, if it is still fresh.
+func (c *routeCache) get(key string, now time.Time) (*routePlan, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if ok && now.Sub(el.Value.(*routeCacheEntry).created) > routeCacheTTL {
+		c.order.Remove(el)
+		delete(c.items, key)
+		ok = false
+	}
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(el)
+	return el.Value.(*routeCacheEntry).plan, true
+}
+
+// put stores plan under key, evicting the least recently used entry when full.
+func (c *routeCache) put(key string, plan *routePlan, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value = &routeCacheEntry{key: key, plan: plan, created: now}
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&routeCacheEntry{key: key, plan: plan, created: now})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*routeCacheEntry).key)
+	}
+}
+
+// routeCacheStats is what the debug endpoint reports.
+type routeCacheStats struct {
+	Entries int    `json:"entries"`
+	Size    int    `json:"size"`
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+}
+
+func (c *routeCache) stats() routeCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return routeCacheStats{Entries: c.order.Len(), Size: c.size, Hits: c.hits, Misses: c.misses}
+}
+
+// routeCacheKey identifies a Short Circuit query against one overlay
+// version. Everything that changes the systems a route takes goes in.
+func routeCacheKey(version string, waypoints []int, preset routing.Preset, optimise bool, alternatives int, opts routing.RouteOptions) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s|%v|%s|%t|%d|", version, waypoints, preset, optimise, alternatives)
+	fmt.Fprintf(&b, "%s|%s|%t|%s|%+v|", opts.Profile, opts.Ship, opts.Bridges, opts.Algorithm, opts.Wormholes)
+	if a := opts.Avoid; !a.Empty() {
+		fmt.Fprintf(&b, "%v|%v|%v", sortedIDs(a.Systems), sortedIDs(a.Constellations), sortedIDs(a.Regions))
+	}
+	return b.String()
+}
+
+func sortedIDs(set map[int]bool) []int {
+	ids := make([]int, 0, len(set))
+	for id, ok := range set {
+		if ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// routeCacheHandler reports the route cache's hit and miss counters to admins.
+func (s *Server) routeCacheHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.isAdmin(r) {
+		http.Error(w, "Only admins can view the route cache stats.", http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.routes.stats()); err != nil {
+		log.Printf("Failed to encode route cache stats: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"wingspan-ops/internal/routing"
+)
+
+func TestRouteCache(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	type op struct {
+		put     bool // put the key, or get it
+		key     string
+		after   time.Duration // since start
+		wantHit bool
+	}
+	tests := []struct {
+		name        string
+		size        int
+		ops         []op
+		wantEntries int
+	}{
+		{"hit", 2, []op{
+			{true, "a", 0, false},
+			{false, "a", time.Minute, true},
+			{false, "b", time.Minute, false},
+		}, 1},
+		{"evicts least recently used", 2, []op{
+			{true, "a", 0, false},
+			{true, "b", 0, false},
+			{false, "a", 0, true}, // b is now the oldest
+			{true, "c", 0, false},
+			{false, "b", 0, false},
+			{false, "a", 0, true},
+			{false, "c", 0, true},
+		}, 2},
+		{"overwrite keeps one entry", 2, []op{
+			{true, "a", 0, false},
+			{true, "a", 0, false},
+			{false, "a", 0, true},
+		}, 1},
+		{"fresh at the TTL", 2, []op{
+			{true, "a", 0, false},
+			{false, "a", routeCacheTTL, true},
+		}, 1},
+		{"expired after the TTL", 2, []op{
+			{true, "a", 0, false},
+			{false, "a", routeCacheTTL + time.Second, false},
+		}, 0},
+		{"overwrite restarts the TTL", 2, []op{
+			{true, "a", 0, false},
+			{true, "a", routeCacheTTL, false},
+			{false, "a", routeCacheTTL + time.Second, true},
+		}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newRouteCache(tt.size)
+			hits, misses := uint64(0), uint64(0)
+			for i, o := range tt.ops {
+				now := start.Add(o.after)
+				if o.put {
+					c.put(o.key, &routePlan{Nearest: i}, now)
+					continue
+				}
+				plan, ok := c.get(o.key, now)
+				if ok != o.wantHit {
+					t.Errorf("op %d: get(%q) hit = %t, want %t", i, o.key, ok, o.wantHit)
+				}
+				if ok {
+					hits++
+					if plan == nil {
+						t.Errorf("op %d: get(%q) hit with a nil plan", i, o.key)
+					}
+				} else {
+					misses++
+				}
+			}
+			want := routeCacheStats{Entries: tt.wantEntries, Size: tt.size, Hits: hits, Misses: misses}
+			if got := c.stats(); got != want {
+				t.Errorf("stats = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestRouteCacheKey(t *testing.T) {
+	avoid := func(ids ...int) *routing.Avoidance {
+		a := routing.NewAvoidance()
+		for _, id := range ids {
+			a.Systems[id] = true
+		}
+		return a
+	}
+	base := routing.RouteOptions{Profile: routing.ProfileShortest}
+	key := func(version string, waypoints []int, opts routing.RouteOptions) string {
+		return routeCacheKey(version, waypoints, "", false, 0, opts)
+	}
+	withAvoid := func(a *routing.Avoidance) routing.RouteOptions {
+		opts := base
+		opts.Avoid = a
+		return opts
+	}
+	withProfile := base
+	withProfile.Profile = routing.ProfileSafer
+
+	tests := []struct {
+		name     string
+		a, b     string
+		wantSame bool
+	}{
+		{"identical", key("v1", []int{1, 2}, base), key("v1", []int{1, 2}, base), true},
+		{"overlay version", key("v1", []int{1, 2}, base), key("v2", []int{1, 2}, base), false},
+		{"waypoint order", key("v1", []int{1, 2}, base), key("v1", []int{2, 1}, base), false},
+		{"profile", key("v1", []int{1, 2}, base), key("v1", []int{1, 2}, withProfile), false},
+		{"avoid list", key("v1", []int{1, 2}, base), key("v1", []int{1, 2}, withAvoid(avoid(3))), false},
+		{"empty avoid list", key("v1", []int{1, 2}, base), key("v1", []int{1, 2}, withAvoid(avoid())), true},
+		{"avoid list order", key("v1", []int{1, 2}, withAvoid(avoid(3, 4, 5))), key("v1", []int{1, 2}, withAvoid(avoid(5, 4, 3))), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := tt.a == tt.b; same != tt.wantSame {
+				t.Errorf("keys %q and %q: same = %t, want %t", tt.a, tt.b, same, tt.wantSame)
+			}
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"html/template"
 	"net/http"
 	"path/filepath"
+	"strings"
 	"wingspan-ops/internal/esi"
+	"wingspan-ops/internal/models"
 	"wingspan-ops/internal/routing"
 
 	"github.com/gorilla/sessions"
@@ -19,6 +21,23 @@ var functions = template.FuncMap{
 	"add": func(a, b int) int {
 		return a + b
 	},
+	"routeProfiles": func() []routing.ProfileOption {
+		return routing.Profiles
+	},
+	"nearestPresets": func() []routing.PresetOption {
+		return routing.Presets
+	},
+	"shipClasses": func() []routing.ShipClassOption {
+		return routing.ShipClasses
+	},
+	"bridgeList":  bridgeListText,
+	"accessLines": accessLines,
+	"jumpShips": func() []routing.JumpShip {
+		return routing.JumpShips
+	},
+	"join": func(items []string) string {
+		return strings.Join(items, ", ")
+	},
 }
 
 // Server holds all the dependencies required for the web application.
@@ -28,8 +47,19 @@ type Server struct {
 	feedbackURL  string
 	esiClient    *esi.ESIClient
 	graph        *routing.Graph
+	jumpSpace    *routing.JumpSpace // nil when no SDE coordinates were loaded
 	oauthConfig  *oauth2.Config
 	sessionStore *sessions.CookieStore
+	bridgesPath  string         // where the jump bridge list is saved
+	baselinePath string         // where the kill updater keeps each system's hourly kill average
+	admins       map[int]bool   // character IDs allowed to edit shared settings
+	routes       *routeCache    // planned routes, keyed by query and overlay version
+	watches      *watchList     // routes characters want to hear about when they change
+	tokens       *tokenStore    // SSO tokens for ESI calls made on a character's behalf
+	jwks         *jwksCache     // EVE SSO's token signing keys
+	accounts     *accountList   // characters linked to each pilot's account
+	access       *accessControl // who may use the hub
+	avoids       *avoidStore    // each character's saved avoid list
 }
 
 // New creates and initializes a new Server instance.
@@ -37,8 +67,14 @@ func New(
 	wingspanAPIURL, feedbackURL string,
 	esiClient *esi.ESIClient,
 	graph *routing.Graph,
+	jumpSpace *routing.JumpSpace,
 	oauthConfig *oauth2.Config,
 	sessionStore *sessions.CookieStore,
+	bridgesPath string,
+	adminIDs []int,
+	tokensPath, tokenKey string,
+	aclPath string,
+	killBaselinePath string,
 ) (*Server, error) {
 	// Initialize the template cache.
 	cache, err := newTemplateCache("./templates")
@@ -46,6 +82,16 @@ func New(
 		return nil, err
 	}
 
+	tokens, err := newTokenStore(oauthConfig, tokensPath, tokenKey)
+	if err != nil {
+		return nil, err
+	}
+
+	admins := make(map[int]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = true
+	}
+
 	// Create and return the Server instance with all dependencies.
 	return &Server{
 		templates:    cache,
@@ -53,8 +99,19 @@ func New(
 		feedbackURL:  feedbackURL,
 		esiClient:    esiClient,
 		graph:        graph,
+		jumpSpace:    jumpSpace,
 		oauthConfig:  oauthConfig,
 		sessionStore: sessionStore,
+		bridgesPath:  bridgesPath,
+		baselinePath: killBaselinePath,
+		admins:       admins,
+		routes:       newRouteCache(routeCacheSize),
+		watches:      &watchList{path: routeWatchesPath},
+		tokens:       tokens,
+		jwks:         newJWKSCache(eveJWKSURL),
+		accounts:     &accountList{path: accountsPath, accounts: make(map[string][]models.LinkedCharacter)},
+		access:       &accessControl{path: aclPath, acl: defaultAccessList()},
+		avoids:       &avoidStore{path: avoidListsPath, lists: make(map[int]models.AvoidList)},
 	}, nil
 }
 
@@ -77,6 +134,16 @@ func (s *Server) RegisterRoutes() http.Handler {
 	mux.Handle("/short-circuit", s.authMiddleware(http.HandlerFunc(s.shortCircuitHandler)))
 	mux.Handle("/lookup", s.authMiddleware(http.HandlerFunc(s.lookupHandler)))
 	mux.Handle("/about", s.authMiddleware(http.HandlerFunc(s.aboutHandler)))
+	mux.Handle("/reach", s.authMiddleware(http.HandlerFunc(s.reachHandler)))
+	mux.Handle("/capitals", s.authMiddleware(http.HandlerFunc(s.capitalsHandler)))
+	mux.Handle("/bridges", s.authMiddleware(http.HandlerFunc(s.bridgesHandler)))
+	mux.Handle("/settings", s.authMiddleware(http.HandlerFunc(s.settingsHandler)))
+	mux.Handle("/watches", s.authMiddleware(http.HandlerFunc(s.watchesHandler)))
+	mux.Handle("/access", s.authMiddleware(http.HandlerFunc(s.accessHandler)))
+	mux.Handle("/account", s.authMiddleware(http.HandlerFunc(s.accountHandler)))
+	mux.Handle("/auth/sso/link", s.authMiddleware(http.HandlerFunc(s.linkHandler)))
+	mux.Handle("/autopilot", s.authMiddleware(http.HandlerFunc(s.autopilotHandler)))
+	mux.Handle("/debug/route-cache", s.authMiddleware(http.HandlerFunc(s.routeCacheHandler)))
 
 	return mux
 }
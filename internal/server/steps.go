@@ -0,0 +1,131 @@
+package server
+
+import (
+	"time"
+	"wingspan-ops/internal/esi"
+	"wingspan-ops/internal/models"
+	"wingspan-ops/internal/routing"
+)
+
+// stepBuilder turns routes found on a graph snapshot into the steps shown on the route page.
+type stepBuilder struct {
+	graph     *routing.Snapshot
+	opts      routing.RouteOptions
+	esiClient *esi.ESIClient
+	killMap   map[int]esi.EsiSystemKills
+	risk      map[int]float64 // risk score per system; see riskScores
+	now       time.Time
+}
+
+// stitch joins the legs of a waypoint route into one list of steps,
+// numbering each leg and flagging the intermediate waypoints it stops at.
+func (b *stepBuilder) stitch(legs [][]int) []models.PathStep {
+	var path []models.PathStep
+	for i, leg := range legs {
+		steps := b.build(leg)
+		for j := range steps {
+			steps[j].Leg = i + 1
+		}
+		if i > 0 {
+			steps = steps[1:] // the previous leg already ends here
+		}
+		if i < len(legs)-1 && len(steps) > 0 {
+			steps[len(steps)-1].Waypoint = true
+		}
+		path = append(path, steps...)
+	}
+	return path
+}
+
+// summarise builds the steps for one alternative route and totals its
+// jumps, wormhole jumps, kill activity and risk.
+func (b *stepBuilder) summarise(systems []int) models.RouteOption {
+	route := models.RouteOption{
+		Path:  b.build(systems),
+		Jumps: len(systems) - 1,
+	}
+	for i := 0; i+1 < len(systems); i++ {
+		if e, ok := b.graph.Jump(systems[i], systems[i+1], b.opts); ok && e.Link != nil && e.Kind != routing.KindBridge {
+			route.WormholeJumps++
+		}
+	}
+	for _, step := range route.Path {
+		route.ShipKills += step.ShipKills
+		route.PodKills += step.PodKills
+		route.NpcKills += step.NpcKills
+		route.Risk += step.Risk
+		if step.Hotspot {
+			route.Hotspots++
+		}
+	}
+	return route
+}
+
+// build turns a list of system IDs into steps, annotating each wormhole jump.
+func (b *stepBuilder) build(systems []int) []models.PathStep {
+	path := make([]models.PathStep, 0, len(systems))
+	for i, id := range systems {
+		sysInfo, err := b.esiClient.GetSystemDetails(id)
+		kills := b.killMap[id]
+		var step models.PathStep
+		if err != nil {
+			step = models.PathStep{
+				SystemName: b.esiClient.GetSystemName(id),
+				ShipKills:  kills.ShipKills,
+				PodKills:   kills.PodKills,
+				NpcKills:   kills.NpcKills,
+			}
+		} else {
+			step = models.PathStep{
+				SystemName:     sysInfo.Name,
+				SecurityStatus: sysInfo.SecurityStatus,
+				SecurityClass:  routing.SecurityClass(sysInfo.SecurityStatus),
+				ShipKills:      kills.ShipKills,
+				PodKills:       kills.PodKills,
+				NpcKills:       kills.NpcKills,
+			}
+		}
+		step.SystemID = id
+		step.Risk = b.risk[id]
+		step.Hotspot = step.Risk >= routing.HotspotRisk
+		step.Note = b.graph.SpaceOf(id).Note()
+		if i > 0 {
+			b.annotateJump(&step, systems[i-1], id)
+		} else {
+			step.JumpType = "start"
+		}
+		path = append(path, step)
+	}
+	return path
+}
+
+// annotateJump records how a step was reached and, for wormholes, copies the
+// details of the hole used.
+func (b *stepBuilder) annotateJump(step *models.PathStep, from, to int) {
+	e, ok := b.graph.Jump(from, to, b.opts)
+	if !ok {
+		return
+	}
+	step.JumpType = string(e.Kind)
+	if e.Link == nil || e.Kind == routing.KindBridge {
+		return
+	}
+	l := e.Link
+	step.Source = l.Source
+	step.WormholeType = l.Type
+	step.Scout = l.Scout
+	if !l.UpdatedAt.IsZero() {
+		step.LastModified = l.UpdatedAt.UTC().Format("2006-01-02 15:04") + " UTC"
+	}
+	step.FromSignature, step.ToSignature = l.FromSignature, l.ToSignature
+	if l.From != from {
+		// Travelling the link backwards.
+		step.FromSignature, step.ToSignature = l.ToSignature, l.FromSignature
+	}
+	step.Life = l.Life
+	if l.EOL(b.now) {
+		step.Life = "critical"
+	}
+	step.Mass = l.Mass
+	step.HoursLeft = l.HoursLeft(b.now)
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// eveRevokeURL is where EVE SSO takes tokens back on logout.
+	eveRevokeURL = "https://login.eveonline.com/v2/oauth/revoke"
+	// tokenEarlyExpiry is how long before expiry an access token is refreshed,
+	// so a request never goes out with one about to lapse.
+	tokenEarlyExpiry = 2 * time.Minute
+)
+
+// errNoToken means the server holds no SSO token for the character, so it
+// must log in again before anything can be done in game on its behalf.
+var errNoToken = errors.New("no SSO token for this character; log in again")
+
+// errMissingScope means the character didn't grant the scope a call needs
+// when it logged in, so it must log in again and tick it.
+var errMissingScope = errors.New("the character didn't grant the scope this needs; log in again")
+
+// ESI scopes the server checks for before calling ESI on a character's behalf.
+const (
+	scopeWriteWaypoint = "esi-ui.write_waypoint.v1"
+	scopeReadLocation  = "esi-location.read_location.v1"
+)
+
+// savedToken is a character's SSO token along with the scopes the character
+// granted, read from the access token when it logged in.
+type savedToken struct {
+	*oauth2.Token
+	Scopes []string `json:"scopes"`
+}
+
+// tokenStore keeps each character's SSO tokens so the server can call
+// authenticated ESI endpoints on its behalf, refreshing them as they expire.
+// With a key the tokens are saved to disk encrypted with AES-GCM; without
+// one they only live in memory and are lost on restart.
+type tokenStore struct {
+	config *oauth2.Config
+	path   string
+	aead   cipher.AEAD // nil when tokens are kept in memory only
+
+	mu     sync.Mutex
+	tokens map[int]savedToken
+}
+
+// newTokenStore returns a store saving to path, encrypted with key: 32 random
+// bytes, base64 encoded, such as the output of "openssl rand -base64 32". An
+// empty key keeps tokens in memory only.
+func newTokenStore(config *oauth2.Config, path, key string) (*tokenStore, error) {
+	t := &tokenStore{config: config, path: path, tokens: make(map[int]savedToken)}
+	if key == "" {
+		return t, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil || len(raw) != 32 {
+		return nil, errors.New("the token store key must be 32 bytes, base64 encoded; generate one with: openssl rand -base64 32")
+	}
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, err
+	}
+	if t.aead, err = cipher.NewGCM(block); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// load reads the saved tokens. A missing file just means nobody has logged in yet.
+func (t *tokenStore) load() error {
+	if t.aead == nil {
+		return nil
+	}
+	data, err := os.ReadFile(t.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	size := t.aead.NonceSize()
+	if len(data) < size {
+		return fmt.Errorf("%s is too short to be a token file", t.path)
+	}
+	plain, err := t.aead.Open(nil, data[:size], data[size:], nil)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s; has the key changed? %w", t.path, err)
+	}
+	tokens := make(map[int]savedToken)
+	if err := json.Unmarshal(plain, &tokens); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", t.path, err)
+	}
+	t.mu.Lock()
+	t.tokens = tokens
+	t.mu.Unlock()
+	return nil
+}
+
+// save encrypts and writes the tokens. The caller must hold t.mu.
+func (t *tokenStore) save() error {
+	if t.aead == nil {
+		return nil
+	}
+	plain, err := json.Marshal(t.tokens)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, t.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	return writeFileAtomic(t.path, t.aead.Seal(nonce, nonce, plain, nil), 0o600)
+}
+
+// put keeps a character's token and the scopes it granted.
+func (t *tokenStore) put(charID int, token *oauth2.Token, scopes []string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tokens[charID] = savedToken{Token: token, Scopes: scopes}
+	return t.save()
+}
+
+// accessToken returns a valid access token for the character, using the
+// refresh token to get a new one shortly before the old one expires. EVE SSO
+// rotates refresh tokens, so refreshes are done one at a time under the lock.
+// It fails with errMissingScope, without calling SSO, if the character
+// didn't grant scope.
+func (t *tokenStore) accessToken(ctx context.Context, charID int, scope string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	saved, ok := t.tokens[charID]
+	if !ok || saved.Token == nil {
+		return "", errNoToken
+	}
+	if !slices.Contains(saved.Scopes, scope) {
+		return "", errMissingScope
+	}
+	token := saved.Token
+	// The refresher is given only the refresh token, so it always goes to
+	// SSO when asked; the reuse source decides when that is.
+	refresher := t.config.TokenSource(ctx, &oauth2.Token{RefreshToken: token.RefreshToken})
+	fresh, err := oauth2.ReuseTokenSourceWithExpiry(token, refresher, tokenEarlyExpiry).Token()
+	if err != nil {
+		return "", fmt.Errorf("failed to refresh SSO token: %w", err)
+	}
+	if fresh.AccessToken != token.AccessToken {
+		t.tokens[charID] = savedToken{Token: fresh, Scopes: saved.Scopes}
+		if err := t.save(); err != nil {
+			log.Printf("ERROR: Failed to save SSO tokens: %v", err)
+		}
+	}
+	return fresh.AccessToken, nil
+}
+
+// revoke forgets the character's tokens and asks EVE SSO to invalidate the
+// refresh token, so it can't be used again even if the file leaks.
+func (t *tokenStore) revoke(ctx context.Context, charID int) error {
+	t.mu.Lock()
+	token, ok := t.tokens[charID]
+	delete(t.tokens, charID)
+	err := t.save()
+	t.mu.Unlock()
+	if err != nil || !ok || token.Token == nil || token.RefreshToken == "" {
+		return err
+	}
+
+	form := url.Values{"token_type_hint": {"refresh_token"}, "token": {token.RefreshToken}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, eveRevokeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(t.config.ClientID, t.config.ClientSecret)
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call revoke endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("revoke endpoint returned non-200 status: %s", resp.Status)
+	}
+	return nil
+}
+
+// LoadTokens reads the saved SSO tokens.
+func (s *Server) LoadTokens() error {
+	if err := s.tokens.load(); err != nil {
+		return err
+	}
+	if s.tokens.aead == nil {
+		log.Println("WARN: TOKEN_STORE_KEY not set; SSO tokens are kept in memory only.")
+		return nil
+	}
+	log.Printf("✅ Loaded SSO tokens for %d characters.", len(s.tokens.tokens))
+	return nil
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func testTokenKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, 32))
+}
+
+func TestNewTokenStoreKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		wantErr    bool
+		wantCipher bool
+	}{
+		{"no key", "", false, false},
+		{"valid key", testTokenKey('a'), false, true},
+		{"trailing newline", testTokenKey('a') + "\n", false, true},
+		{"not base64", "not a key!", true, false},
+		{"too short", base64.StdEncoding.EncodeToString(make([]byte, 16)), true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := newTokenStore(&oauth2.Config{}, filepath.Join(t.TempDir(), "tokens.enc"), tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %t", err, tt.wantErr)
+			}
+			if err == nil && (store.aead != nil) != tt.wantCipher {
+				t.Errorf("encrypted = %t, want %t", store.aead != nil, tt.wantCipher)
+			}
+		})
+	}
+}
+
+func TestTokenStoreRoundTrip(t *testing.T) {
+	token := &oauth2.Token{
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		TokenType:    "Bearer",
+		Expiry:       time.Now().Add(time.Hour).Round(time.Second),
+	}
+	scopes := []string{scopeReadLocation, scopeWriteWaypoint}
+
+	tests := []struct {
+		name      string
+		saveKey   string
+		loadKey   string
+		wantErr   bool
+		wantToken bool
+	}{
+		{"same key", testTokenKey('a'), testTokenKey('a'), false, true},
+		{"wrong key", testTokenKey('a'), testTokenKey('b'), true, false},
+		{"memory only", "", testTokenKey('a'), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tokens.enc")
+			saver, err := newTokenStore(&oauth2.Config{}, path, tt.saveKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := saver.put(90000001, token, scopes); err != nil {
+				t.Fatalf("put: %v", err)
+			}
+			if data, err := os.ReadFile(path); err == nil && strings.Contains(string(data), "refresh") {
+				t.Error("token file holds the refresh token in plain text")
+			}
+
+			loader, err := newTokenStore(&oauth2.Config{}, path, tt.loadKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = loader.load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("load error = %v, want error %t", err, tt.wantErr)
+			}
+			got, ok := loader.tokens[90000001]
+			if ok != tt.wantToken {
+				t.Fatalf("token loaded = %t, want %t", ok, tt.wantToken)
+			}
+			if !ok {
+				return
+			}
+			if got.AccessToken != token.AccessToken || got.RefreshToken != token.RefreshToken || !got.Expiry.Equal(token.Expiry) {
+				t.Errorf("got token %+v, want %+v", got.Token, token)
+			}
+			if !slices.Equal(got.Scopes, scopes) {
+				t.Errorf("got scopes %v, want %v", got.Scopes, scopes)
+			}
+		})
+	}
+}
+
+func TestTokenStoreAccessTokenScopes(t *testing.T) {
+	store, err := newTokenStore(&oauth2.Config{}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)}
+	store.put(1, valid, []string{scopeReadLocation})
+	store.put(2, valid, nil) // saved before scopes were kept
+
+	tests := []struct {
+		name    string
+		charID  int
+		scope   string
+		want    string
+		wantErr error
+	}{
+		{"granted", 1, scopeReadLocation, "access", nil},
+		{"not granted", 1, scopeWriteWaypoint, "", errMissingScope},
+		{"no scopes saved", 2, scopeReadLocation, "", errMissingScope},
+		{"no token", 3, scopeReadLocation, "", errNoToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.accessToken(context.Background(), tt.charID, tt.scope)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,411 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"wingspan-ops/internal/models"
+	"wingspan-ops/internal/routing"
+)
+
+const (
+	// routeWatchesPath is where watched routes are saved.
+	routeWatchesPath = "route_watches.json"
+	// maxWatches caps how many routes one character may watch.
+	maxWatches = 10
+)
+
+// discordWebhookPrefixes are the only URLs a watch may post to.
+var discordWebhookPrefixes = []string{
+	"https://discord.com/api/webhooks/",
+	"https://discordapp.com/api/webhooks/",
+}
+
+// watchList holds every character's watched routes and keeps them on disk.
+type watchList struct {
+	mu      sync.Mutex
+	path    string
+	watches []models.RouteWatch
+}
+
+// load reads the saved watches.
+func (l *watchList) load() error {
+	var watches []models.RouteWatch
+	if _, err := loadJSON(l.path, &watches); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.watches = watches
+	l.mu.Unlock()
+	return nil
+}
+
+// save writes the watches. The caller must hold l.mu.
+func (l *watchList) save() error {
+	return saveJSON(l.path, l.watches)
+}
+
+// forCharacter returns a copy of the watches belonging to one character.
+func (l *watchList) forCharacter(charID int) []models.RouteWatch {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var watches []models.RouteWatch
+	for _, w := range l.watches {
+		if w.CharacterID == charID {
+			watches = append(watches, w)
+		}
+	}
+	return watches
+}
+
+// LoadWatches reads the saved route watches.
+func (s *Server) LoadWatches() error {
+	if err := s.watches.load(); err != nil {
+		return err
+	}
+	log.Printf("✅ Loaded %d route watches.", len(s.watches.watches))
+	return nil
+}
+
+// watchesHandler lists the character's watched routes and adds or removes
+// them. Notices are cleared once this page has shown them.
+func (s *Server) watchesHandler(w http.ResponseWriter, r *http.Request) {
+	charID, ok := s.characterID(r)
+	if !ok {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		var err error
+		switch r.FormValue("action") {
+		case "add":
+			err = s.addWatch(r, charID)
+		case "remove":
+			err = s.removeWatch(charID, r.FormValue("id"))
+		default:
+			err = fmt.Errorf("unknown action %q", r.FormValue("action"))
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Redirect(w, r, "/watches", http.StatusSeeOther)
+		return
+	}
+
+	data := models.FrontendData{
+		FeedbackURL:   s.feedbackURL,
+		CharacterName: s.getAuthenticatedUser(r),
+		Characters:    s.linkedCharacters(r),
+		Watches:       s.watches.forCharacter(charID),
+	}
+	ts, ok := s.templates["watches.html"]
+	if !ok {
+		http.Error(w, "Could not load watches.html template", http.StatusInternalServerError)
+		return
+	}
+	if err := ts.Execute(w, data); err != nil {
+		log.Printf("Template execution error: %v", err)
+	}
+	s.clearNotices(charID)
+}
+
+// addWatch saves the route described by the form as a new watch, planned
+// against the current overlay so only later changes raise a notice.
+func (s *Server) addWatch(r *http.Request, charID int) error {
+	watch := models.RouteWatch{
+		CharacterID: charID,
+		Start:       r.FormValue("start_system"),
+		End:         r.FormValue("end_system"),
+		Profile:     string(routing.ParseProfile(r.FormValue("profile"))),
+		ShipClass:   string(routing.ParseShipClass(r.FormValue("ship_class"))),
+		UseBridges:  r.FormValue("use_bridges") == "on",
+		Avoid:       avoidListFromForm(r),
+		Webhook:     strings.TrimSpace(r.FormValue("webhook")),
+		CreatedAt:   time.Now(),
+	}
+	if watch.Webhook != "" && !isDiscordWebhook(watch.Webhook) {
+		return errors.New("the webhook must be a Discord webhook URL")
+	}
+	if r.FormValue("use_saved_avoid") == "on" {
+		saved := s.savedAvoidList(r)
+		watch.Avoid.Systems = append(watch.Avoid.Systems, saved.Systems...)
+		watch.Avoid.Constellations = append(watch.Avoid.Constellations, saved.Constellations...)
+		watch.Avoid.Regions = append(watch.Avoid.Regions, saved.Regions...)
+	}
+	var err error
+	if watch.StartID, err = s.esiClient.GetSystemID(r.Context(), watch.Start); err != nil {
+		return fmt.Errorf("could not find start system: %s", watch.Start)
+	}
+	if watch.EndID, err = s.esiClient.GetSystemID(r.Context(), watch.End); err != nil {
+		return fmt.Errorf("could not find end system: %s", watch.End)
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	watch.ID = hex.EncodeToString(id)
+
+	avoid, err := s.resolveAvoidance(r.Context(), watch.Avoid)
+	if err != nil {
+		return err
+	}
+	watch.AvoidIDs = avoidIDs(avoid)
+
+	snap := s.graph.Snapshot()
+	watch.Systems = s.planWatch(snap, watch, s.riskScores(readKillMap()))
+	watch.Version = snap.Overlay().Version()
+
+	s.watches.mu.Lock()
+	defer s.watches.mu.Unlock()
+	count := 0
+	for _, w := range s.watches.watches {
+		if w.CharacterID == charID {
+			count++
+		}
+	}
+	if count >= maxWatches {
+		return fmt.Errorf("you can watch at most %d routes", maxWatches)
+	}
+	s.watches.watches = append(s.watches.watches, watch)
+	return s.watches.save()
+}
+
+// removeWatch deletes one of the character's watches.
+func (s *Server) removeWatch(charID int, id string) error {
+	s.watches.mu.Lock()
+	defer s.watches.mu.Unlock()
+	for i, w := range s.watches.watches {
+		if w.ID == id && w.CharacterID == charID {
+			s.watches.watches = append(s.watches.watches[:i], s.watches.watches[i+1:]...)
+			return s.watches.save()
+		}
+	}
+	return errors.New("no such watch")
+}
+
+// watchNotices returns the logged-in character's watches with unseen changes, for the page banner.
+func (s *Server) watchNotices(r *http.Request) []models.RouteWatch {
+	charID, ok := s.characterID(r)
+	if !ok {
+		return nil
+	}
+	var notices []models.RouteWatch
+	for _, w := range s.watches.forCharacter(charID) {
+		if w.Notice != "" {
+			notices = append(notices, w)
+		}
+	}
+	return notices
+}
+
+// clearNotices marks every notice on the character's watches as seen.
+func (s *Server) clearNotices(charID int) {
+	s.watches.mu.Lock()
+	defer s.watches.mu.Unlock()
+	cleared := false
+	for i := range s.watches.watches {
+		if w := &s.watches.watches[i]; w.CharacterID == charID && w.Notice != "" {
+			w.Notice = ""
+			cleared = true
+		}
+	}
+	if cleared {
+		if err := s.watches.save(); err != nil {
+			log.Printf("ERROR: Failed to save route watches: %v", err)
+		}
+	}
+}
+
+// checkWatches re-plans every watch not yet planned against the current
+// overlay, and raises a notice when its route got shorter or lost a hole.
+// Notices with a webhook are also posted to Discord. The watches are planned
+// from a copy, so pages showing notices aren't held up while it runs.
+func (s *Server) checkWatches() {
+	snap := s.graph.Snapshot()
+	version := snap.Overlay().Version()
+
+	s.watches.mu.Lock()
+	var due []models.RouteWatch
+	for _, w := range s.watches.watches {
+		if w.Version != version {
+			due = append(due, w)
+		}
+	}
+	s.watches.mu.Unlock()
+	if len(due) == 0 {
+		return
+	}
+
+	type result struct {
+		watch  models.RouteWatch
+		notice string // raised by this check, if any
+	}
+	risk := s.riskScores(readKillMap())
+	planned := make(map[string]result, len(due))
+	for _, w := range due {
+		if w.AvoidIDs == nil {
+			avoid, err := s.resolveAvoidance(context.Background(), w.Avoid)
+			if err != nil {
+				log.Printf("[WATCHER] WARN: Could not re-plan %s → %s: %v", w.Start, w.End, err)
+				continue
+			}
+			w.AvoidIDs = avoidIDs(avoid)
+		}
+		opts := s.watchOptions(w, watchAvoidance(w), risk)
+		systems := snap.Path(w.StartID, w.EndID, opts)
+		notice := s.watchChange(snap, w, systems, opts)
+		// Characters who have lost access keep their watches, in case it
+		// comes back, but nothing more is posted for them.
+		if allowed, _ := s.accounts.allowed(w.CharacterID); notice != "" && w.Webhook != "" && allowed {
+			if err := postDiscord(w.Webhook, notice); err != nil {
+				log.Printf("[WATCHER] WARN: Discord webhook failed: %v", err)
+			}
+		}
+		w.Systems, w.Version = systems, version
+		planned[w.ID] = result{w, notice}
+	}
+
+	// Write the results back to whichever watches still exist; any removed
+	// in the meantime stay removed.
+	s.watches.mu.Lock()
+	defer s.watches.mu.Unlock()
+	for i := range s.watches.watches {
+		w := &s.watches.watches[i]
+		p, ok := planned[w.ID]
+		if !ok {
+			continue
+		}
+		w.AvoidIDs, w.Systems, w.Version = p.watch.AvoidIDs, p.watch.Systems, p.watch.Version
+		if p.notice != "" {
+			w.Notice, w.NoticeAt = p.notice, time.Now()
+		}
+	}
+	if err := s.watches.save(); err != nil {
+		log.Printf("[WATCHER] ERROR: Failed to save route watches: %v", err)
+	}
+}
+
+// planWatch plans a watched route against snap, returning nil if no route exists.
+func (s *Server) planWatch(snap *routing.Snapshot, w models.RouteWatch, risk map[int]float64) []int {
+	return snap.Path(w.StartID, w.EndID, s.watchOptions(w, watchAvoidance(w), risk))
+}
+
+// avoidIDs records the IDs an avoid list resolved to.
+func avoidIDs(avoid *routing.Avoidance) *models.AvoidIDs {
+	ids := &models.AvoidIDs{}
+	for id := range avoid.Systems {
+		ids.Systems = append(ids.Systems, id)
+	}
+	for id := range avoid.Constellations {
+		ids.Constellations = append(ids.Constellations, id)
+	}
+	for id := range avoid.Regions {
+		ids.Regions = append(ids.Regions, id)
+	}
+	return ids
+}
+
+// watchAvoidance rebuilds a watch's avoidance from the IDs saved with it.
+func watchAvoidance(w models.RouteWatch) *routing.Avoidance {
+	avoid := routing.NewAvoidance()
+	if w.AvoidIDs == nil {
+		return avoid
+	}
+	for _, id := range w.AvoidIDs.Systems {
+		avoid.Systems[id] = true
+	}
+	for _, id := range w.AvoidIDs.Constellations {
+		avoid.Constellations[id] = true
+	}
+	for _, id := range w.AvoidIDs.Regions {
+		avoid.Regions[id] = true
+	}
+	return avoid
+}
+
+// watchOptions rebuilds the route options a watch was saved with.
+func (s *Server) watchOptions(w models.RouteWatch, avoid *routing.Avoidance, risk map[int]float64) routing.RouteOptions {
+	return routing.RouteOptions{
+		Profile:  routing.ParseProfile(w.Profile),
+		Security: s.esiClient.GetSecurityStatus,
+		Risk:     func(id int) float64 { return risk[id] },
+		Avoid:    avoid,
+		Ship:     routing.ParseShipClass(w.ShipClass),
+		Bridges:  w.UseBridges,
+		Now:      time.Now(),
+	}
+}
+
+// watchChange describes how a watched route changed on its way to systems,
+// or returns "" if there is nothing worth telling the pilot. Routes are
+// compared on their cost under the watch's own options, so a safer or
+// least-risk watch isn't told about a route it chose not to take.
+func (s *Server) watchChange(snap *routing.Snapshot, w models.RouteWatch, systems []int, opts routing.RouteOptions) string {
+	route := fmt.Sprintf("%s → %s", w.Start, w.End)
+	now := len(systems) - 1
+
+	// A hole on the old route has collapsed if the two systems it joined are
+	// no longer connected at all.
+	for i := 1; i < len(w.Systems); i++ {
+		if _, ok := snap.Jump(w.Systems[i-1], w.Systems[i], opts); ok {
+			continue
+		}
+		lost := fmt.Sprintf("%s: the connection from %s to %s has collapsed",
+			route, s.esiClient.GetSystemName(w.Systems[i-1]), s.esiClient.GetSystemName(w.Systems[i]))
+		if systems == nil {
+			return lost + " and there is no longer a route."
+		}
+		return fmt.Sprintf("%s. The route is now %d jumps (was %d).", lost, now, w.Jumps())
+	}
+
+	switch {
+	case systems == nil:
+		return ""
+	case w.Systems == nil:
+		return fmt.Sprintf("%s: a route has opened up, %d jumps.", route, now)
+	case snap.PathCost(systems, opts) >= snap.PathCost(w.Systems, opts):
+		return ""
+	case routing.ParseProfile(w.Profile) == routing.ProfileShortest:
+		return fmt.Sprintf("%s: a shorter route has opened up, %d jumps instead of %d.", route, now, w.Jumps())
+	}
+	return fmt.Sprintf("%s: a better %s route has opened up, %d jumps (was %d).", route, routing.ParseProfile(w.Profile), now, w.Jumps())
+}
+
+// isDiscordWebhook reports whether url points at a Discord webhook.
+func isDiscordWebhook(url string) bool {
+	for _, prefix := range discordWebhookPrefixes {
+		if strings.HasPrefix(url, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// postDiscord sends a plain message to a Discord webhook.
+func postDiscord(url, content string) error {
+	body, err := json.Marshal(map[string]string{"content": content})
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("discord returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -3,24 +3,37 @@ package updater // Change the package name
 import (
 	"context" // Add context for the ESI call
 	"encoding/json"
+	"errors"
 	"log"
 	"os"
 	"sync"
 	"time"
 	"wingspan-ops/internal/esi" // Import your esi package
+	"wingspan-ops/internal/models"
 )
 
 // Renamed to Updater for simplicity
 type Updater struct {
-	esiClient *esi.ESIClient
-	filePath  string
+	esiClient    *esi.ESIClient
+	filePath     string
+	baselinePath string // where the per-system kill baseline is kept
 }
 
+// baselineHours is the span, in hourly samples, the kill baseline averages over.
+const baselineHours = 168
+
+// baselineMinAge is how old the baseline must be before another sample is
+// folded in, so a restart doesn't count the same hour twice. It is a little
+// under an hour so the hourly tick isn't skipped when one ESI call is slower
+// than the last.
+const baselineMinAge = 50 * time.Minute
+
 // Renamed to New
-func New(client *esi.ESIClient, filePath string) *Updater {
+func New(client *esi.ESIClient, filePath, baselinePath string) *Updater {
 	return &Updater{
-		esiClient: client,
-		filePath:  filePath,
+		esiClient:    client,
+		filePath:     filePath,
+		baselinePath: baselinePath,
 	}
 }
 
@@ -65,4 +78,62 @@ func (u *Updater) fetchAndSave() {
 	}
 
 	log.Printf("[UPDATER] ✅ Successfully saved kill data to %s.", u.filePath)
+
+	if err := u.updateBaseline(kills); err != nil {
+		log.Printf("[UPDATER] ERROR: Failed to update kill baseline: %v", err)
+	}
+}
+
+// updateBaseline folds the latest hour of kills into each system's
+// exponentially weighted average. Systems with no kills this hour decay
+// towards zero.
+func (u *Updater) updateBaseline(kills []esi.EsiSystemKills) error {
+	baseline := models.KillBaseline{Systems: make(map[int]float64)}
+	if data, err := os.ReadFile(u.baselinePath); err == nil {
+		if err := json.Unmarshal(data, &baseline); err != nil {
+			return err
+		}
+		if baseline.Systems == nil {
+			baseline.Systems = make(map[int]float64)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if age := time.Since(baseline.UpdatedAt); age < baselineMinAge {
+		log.Printf("[UPDATER] Kill baseline was updated %s ago; not counting this hour again.", age.Round(time.Minute))
+		return nil
+	}
+
+	recent := make(map[int]float64, len(kills))
+	for _, k := range kills {
+		recent[k.SystemID] = float64(k.ShipKills + k.PodKills)
+	}
+
+	// Until a full span has been seen, weight each sample equally so the
+	// first hour doesn't become the baseline on its own.
+	baseline.Samples++
+	alpha := 2.0 / (baselineHours + 1)
+	if warmup := 1.0 / float64(baseline.Samples); warmup > alpha {
+		alpha = warmup
+	}
+	for id, avg := range baseline.Systems {
+		baseline.Systems[id] = avg + alpha*(recent[id]-avg)
+	}
+	for id, n := range recent {
+		if _, ok := baseline.Systems[id]; !ok {
+			// New to the baseline: every earlier sample counts as zero.
+			baseline.Systems[id] = alpha * n
+		}
+	}
+	baseline.UpdatedAt = time.Now().UTC()
+
+	data, err := json.Marshal(baseline)
+	if err != nil {
+		return err
+	}
+	tmp := u.baselinePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, u.baselinePath)
 }